@@ -0,0 +1,14 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : __init__.py
+# @Software: PyCharm
+from clients.http.client import RetryingAsyncClient, create_http_client, http_client_options
+
+__all__ = [
+    'RetryingAsyncClient',
+    'create_http_client',
+    'http_client_options',
+]
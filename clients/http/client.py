@@ -0,0 +1,91 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : client
+# @Software: PyCharm
+import asyncio
+import random
+import time
+
+import httpx
+from loguru import logger
+from prometheus_client import Counter, Histogram
+
+from setting.setting import HttpClientSettings, get_we0_index_settings
+
+OUTBOUND_LATENCY = Histogram(
+    'we0_index_outbound_request_duration_seconds',
+    'Outbound HTTP request latency in seconds, per attempt',
+    ['host', 'method', 'status']
+)
+OUTBOUND_RETRIES = Counter(
+    'we0_index_outbound_retries_total',
+    'Outbound HTTP requests retried after a 5xx or network error',
+    ['host']
+)
+
+
+class RetryingAsyncClient(httpx.AsyncClient):
+    """
+    出站请求统一使用的 httpx 客户端：
+    网络错误与 5xx 响应按抖动指数退避重试，4xx 属于调用方错误，不重试
+    每次尝试的耗时与重试次数记录到 Prometheus 指标
+    """
+
+    def __init__(self, *args, retries: int = 0, backoff: float = 0.5, max_backoff: float = 10, **kwargs):
+        super().__init__(*args, **kwargs)
+        self.retries = retries
+        self.backoff = backoff
+        self.max_backoff = max_backoff
+
+    async def send(self, request: httpx.Request, **kwargs) -> httpx.Response:
+        host = request.url.host
+        for attempt in range(self.retries + 1):
+            start = time.perf_counter()
+            try:
+                response = await super().send(request, **kwargs)
+            except httpx.TransportError as e:
+                OUTBOUND_LATENCY.labels(host=host, method=request.method, status='error').observe(
+                    time.perf_counter() - start
+                )
+                if attempt == self.retries:
+                    raise
+                reason = f"{type(e).__name__}: {e}"
+            else:
+                OUTBOUND_LATENCY.labels(host=host, method=request.method, status=str(response.status_code)).observe(
+                    time.perf_counter() - start
+                )
+                if response.status_code < 500 or attempt == self.retries:
+                    return response
+                await response.aclose()
+                reason = f"HTTP {response.status_code}"
+            # full jitter，避免大量请求在同一时刻重试
+            delay = random.uniform(0, min(self.max_backoff, self.backoff * 2 ** attempt))
+            OUTBOUND_RETRIES.labels(host=host).inc()
+            logger.warning(
+                f"Outbound {request.method} {request.url} failed ({reason}), "
+                f"retrying in {delay:.2f}s ({attempt + 1}/{self.retries})"
+            )
+            await asyncio.sleep(delay)
+        raise RuntimeError('unreachable')
+
+
+def http_client_options(http_client: HttpClientSettings | None = None) -> dict:
+    """RetryingAsyncClient 的超时、重试与连接池参数，子类客户端（如 jina）同样使用"""
+    http_client = http_client or get_we0_index_settings().http_client
+    return dict(
+        timeout=httpx.Timeout(http_client.timeout, connect=http_client.connect_timeout),
+        retries=http_client.retries,
+        backoff=http_client.backoff,
+        max_backoff=http_client.max_backoff,
+        limits=httpx.Limits(
+            max_connections=http_client.max_connections,
+            max_keepalive_connections=http_client.max_keepalive_connections,
+        ),
+    )
+
+
+def create_http_client(**kwargs) -> RetryingAsyncClient:
+    return RetryingAsyncClient(**{**http_client_options(), **kwargs})
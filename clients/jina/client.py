@@ -8,16 +8,15 @@
 import os
 from typing import Optional
 
-import httpx
+from clients.http import RetryingAsyncClient, http_client_options
 
 
-class AsyncClient(httpx.AsyncClient):
+class AsyncClient(RetryingAsyncClient):
 
     def __init__(
             self,
             base_url: Optional[str] = None,
             api_key: Optional[str] = None,
-            timeout: Optional[int] = 300,
             *args, **kwargs
     ):
         if api_key is None:
@@ -33,7 +32,7 @@ class AsyncClient(httpx.AsyncClient):
         if base_url is None:
             base_url = f"https://api.jina.ai/v1"
 
-        super().__init__(base_url=base_url, timeout=timeout, *args, **kwargs)
+        super().__init__(*args, **{**http_client_options(), 'base_url': base_url, **kwargs})
 
         from .embeddings import AsyncEmbeddings
         self.embeddings = AsyncEmbeddings(self)
@@ -35,19 +35,40 @@ class InterceptHandler(logging.Handler):
         logger.opt(depth=depth, exception=record.exc_info).log(level, record.getMessage())
 
 
+class LevelFilter:
+    """
+    以过滤器控制日志级别：调整级别只需替换阈值，无需重建 handler，
+    不会在切换瞬间丢失或重复日志，也不会为文件 sink 新建日志文件
+    """
+
+    def __init__(self, level: str):
+        self.set_level(level)
+
+    def set_level(self, level: str) -> None:
+        # 名称与阈值一并替换为新元组，读取方不会看到不一致的组合
+        self.level = (level.upper(), logger.level(level.upper()).no)
+
+    def __call__(self, record) -> bool:
+        return record['level'].no >= self.level[1]
+
+
 class Log:
     file_name = Constants.Common.PROJECT_NAME + '_{time}' + '.log'
     log_file_path = os.path.join(Constants.Path.LOG_PATH, file_name)
     logger_level = sider_settings.log.level
     logger_file = sider_settings.log.file
+    # json 格式下每条日志输出为一行 JSON，bind 的字段（如访问日志的 method/path/status/latency）位于 record.extra
+    logger_serialize = sider_settings.log.format == 'json'
+    level_filter = LevelFilter(logger_level)
     DEFAULT_CONFIG = [
         {
             'sink': sys.stdout,
-            'level': logger_level,
+            'level': 0,
+            'filter': level_filter,
             'format': '[<green>{time:YYYY-MM-DD HH:mm:ss.SSS}</green>][<level>{level}</level>]'
-                      '[<yellow>{file}</yellow>:<cyan>{line}</cyan>]: <level>{message}</level>',
-            'colorize': True,  # 自定义配色
-            'serialize': False,  # 序列化数据打印
+                      '[<magenta>{extra[request_id]}</magenta>][<yellow>{file}</yellow>:<cyan>{line}</cyan>]: <level>{message}</level>',
+            'colorize': not logger_serialize,  # 自定义配色
+            'serialize': logger_serialize,  # 序列化数据打印
             'backtrace': True,  # 是否显示完整的异常堆栈跟踪
             'diagnose': True,  # 异常跟踪是否显示触发异常的方法或语句所使用的变量，生产环境应设为 False
             'enqueue': False,  # 默认线程安全。若想实现协程安全 或 进程安全，该参数设为 True
@@ -55,13 +76,15 @@ class Log:
 
         }
     ]
+    DEFAULT_EXTRA = {'request_id': '-'}  # 请求上下文之外的日志没有 request id
     if logger_file:
         DEFAULT_CONFIG.append({
             'sink': log_file_path,
-            'level': logger_level,
-            'format': '[{time:YYYY-MM-DD HH:mm:ss.SSS}][{level}][{file}:{line}]: {message}',
+            'level': 0,
+            'filter': level_filter,
+            'format': '[{time:YYYY-MM-DD HH:mm:ss.SSS}][{level}][{extra[request_id]}][{file}:{line}]: {message}',
             'retention': '7 days',  # 日志保留时间
-            'serialize': False,  # 序列化数据打印
+            'serialize': logger_serialize,  # 序列化数据打印
             'backtrace': True,  # 是否显示完整的异常堆栈跟踪
             'diagnose': True,  # 异常跟踪是否显示触发异常的方法或语句所使用的变量，生产环境应设为 False
             'enqueue': False,  # 默认线程安全。若想实现协程安全 或 进程安全，该参数设为 True
@@ -74,13 +97,21 @@ class Log:
             Constants.Path.LOG_PATH
         )
         if config:
-            logger.configure(handlers=config)
+            logger.configure(handlers=config, extra=Log.DEFAULT_EXTRA)
         else:
-            logger.configure(handlers=Log.DEFAULT_CONFIG)
+            logger.configure(handlers=Log.DEFAULT_CONFIG, extra=Log.DEFAULT_EXTRA)
         if sider_settings.log.debug:
             logging.basicConfig(handlers=[InterceptHandler()], level=0, force=True)
         logger.enable('__main__')
 
+    @staticmethod
+    def set_level(level: str) -> None:
+        Log.level_filter.set_level(level)
+
+    @staticmethod
+    def get_level() -> str:
+        return Log.level_filter.level[0]
+
     @staticmethod
     def close() -> None:
         logger.disable('__main__')
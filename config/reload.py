@@ -0,0 +1,57 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : reload
+# @Software: PyCharm
+import asyncio
+import signal
+
+from loguru import logger
+
+from config.loguru import Log
+from extensions.ext_manager import ExtManager
+from setting.setting import get_we0_index_settings, reload_we0_index_settings
+
+
+# 持有后台任务的引用，避免任务在完成前被回收
+_background_tasks = set()
+
+
+async def reload_credentials() -> None:
+    try:
+        await ExtManager.vector.reload_credentials()
+    except Exception as e:
+        logger.error(f"Failed to reload vector database credentials: {e}")
+
+
+def reload_settings() -> None:
+    previous_mode = get_we0_index_settings().maintenance.mode
+    try:
+        settings, ignored = reload_we0_index_settings()
+    except Exception as e:
+        logger.error(f"Failed to reload settings, keeping current values: {e}")
+        return
+    Log.set_level(settings.log.level)
+    if settings.maintenance.mode != previous_mode:
+        logger.warning(f"Maintenance mode changed: {previous_mode} -> {settings.maintenance.mode}")
+    for name in ignored:
+        logger.warning(f"Setting '{name}' changed, change ignored until restart")
+    logger.info("Settings reloaded")
+    task = asyncio.get_running_loop().create_task(reload_credentials())
+    _background_tasks.add(task)
+    task.add_done_callback(_background_tasks.discard)
+
+
+def install_reload_handler() -> None:
+    """收到 SIGHUP 时重新加载可热更新的配置（日志级别、限流、CORS、维护模式、API Key），并使轮换后的数据库凭证生效"""
+    if not hasattr(signal, 'SIGHUP'):
+        return
+    asyncio.get_running_loop().add_signal_handler(signal.SIGHUP, reload_settings)
+
+
+def remove_reload_handler() -> None:
+    if not hasattr(signal, 'SIGHUP'):
+        return
+    asyncio.get_running_loop().remove_signal_handler(signal.SIGHUP)
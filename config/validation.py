@@ -2,49 +2,101 @@
 # -*- coding: utf-8 -*-
 
 import os
+import re
+from typing import List
+
 from loguru import logger
+from pydantic import ValidationError
+
+from domain.enums.vector_type import VectorType
+from setting.setting import We0IndexSettings, get_we0_index_settings
+
+SHA256_PATTERN = re.compile(r'^[0-9a-f]{64}$')
+
+
+class ConfigurationError(Exception):
+    """配置不合法，problems 汇总了全部问题而不仅是第一个"""
+
+    def __init__(self, problems: List[str]):
+        self.problems = problems
+        super().__init__('Invalid configuration:\n' + '\n'.join(f'  - {problem}' for problem in problems))
+
+
+def collect_problems(settings: We0IndexSettings) -> List[str]:
+    """类型与取值范围由 pydantic 校验，这里补充跨字段与运行环境相关的检查"""
+    problems = []
+
+    if not 1 <= settings.server.port <= 65535:
+        problems.append(f'server.port: must be between 1 and 65535, got {settings.server.port}')
+
+    tls = settings.server.tls
+    if tls.enabled:
+        for name, path in (('cert', tls.cert), ('key', tls.key)):
+            if not os.path.isfile(path):
+                problems.append(f'server.tls.{name}: file not found: {path}')
+
+    names = set()
+    for api_key in settings.auth.api_keys:
+        if api_key.name in names:
+            problems.append(f'auth.api-keys: duplicate name: {api_key.name}')
+        names.add(api_key.name)
+        if not SHA256_PATTERN.match(api_key.hash):
+            problems.append(f'auth.api-keys.{api_key.name}.hash: must be a lowercase hex SHA-256 digest')
+
+    platform = settings.vector.platform
+    platform_settings = getattr(settings.vector, platform, None)
+    if platform_settings is None:
+        problems.append(f'vector.{platform}: configuration is missing for platform {platform}')
+    elif platform == VectorType.PGVECTOR:
+        for field in ('host', 'db', 'user'):
+            if not getattr(platform_settings, field):
+                problems.append(f'vector.pgvector.{field}: must not be empty')
+        if not 1 <= platform_settings.port <= 65535:
+            problems.append(f'vector.pgvector.port: must be between 1 and 65535, got {platform_settings.port}')
+        if platform_settings.password_file and not os.path.isfile(platform_settings.password_file):
+            problems.append(f'vector.pgvector.password_file: file not found: {platform_settings.password_file}')
+        if platform_settings.sslmode in ('verify-ca', 'verify-full') and not platform_settings.sslrootcert:
+            problems.append(f'vector.pgvector.sslrootcert: required when sslmode is {platform_settings.sslmode}')
+        elif platform_settings.sslrootcert and not os.path.isfile(platform_settings.sslrootcert):
+            problems.append(f'vector.pgvector.sslrootcert: file not found: {platform_settings.sslrootcert}')
+
+    return problems
+
+
+def load_settings() -> We0IndexSettings:
+    """读取并校验配置，任一问题都会以 ConfigurationError 汇总抛出"""
+    try:
+        settings = get_we0_index_settings()
+    except ValidationError as e:
+        raise ConfigurationError([
+            f"{'.'.join(str(loc) for loc in error['loc'])}: {error['msg']}" for error in e.errors()
+        ]) from e
+    if settings is None:
+        raise ConfigurationError(['we0-index: section is missing'])
+    problems = collect_problems(settings)
+    if problems:
+        raise ConfigurationError(problems)
+    return settings
+
 
 def validate_environment():
     """Validate environment configuration"""
     try:
-        from setting.setting import get_we0_index_settings
-        settings = get_we0_index_settings()
-        
-        if not settings:
-            logger.error("Failed to load settings")
-            return False
-            
-        # Validate vector database configuration
-        if settings.vector.platform == "pgvector":
-            if not settings.vector.pgvector:
-                logger.error("PgVector configuration is missing")
-                return False
-                
-        elif settings.vector.platform == "qdrant":
-            if not settings.vector.qdrant:
-                logger.error("Qdrant configuration is missing") 
-                return False
-                
-        elif settings.vector.platform == "chroma":
-            if not settings.vector.chroma:
-                logger.error("Chroma configuration is missing")
-                return False
-        
-        # Check API keys
-        if settings.vector.embedding_provider == "openai":
-            if not os.environ.get("OPENAI_API_KEY"):
-                logger.warning("OPENAI_API_KEY not set")
-                
-        if settings.vector.embedding_provider == "jina":
-            if not os.environ.get("JINA_API_KEY"):
-                logger.warning("JINA_API_KEY not set")
-        
-        logger.info("Environment validation completed")
-        return True
-        
-    except Exception as e:
-        logger.error(f"Validation failed: {e}")
+        settings = load_settings()
+    except ConfigurationError as e:
+        for problem in e.problems:
+            logger.error(problem)
         return False
 
+    # API Key 可能由 .env 在启动后注入，这里只做提示
+    for provider in {settings.vector.embedding_provider, settings.vector.chat_provider}:
+        env_name = f'{provider.upper()}_API_KEY'
+        if provider in ('openai', 'jina') and not os.environ.get(env_name):
+            logger.warning(f"{env_name} not set")
+
+    logger.info("Environment validation completed")
+    return True
+
+
 if __name__ == "__main__":
     validate_environment()
@@ -14,6 +14,13 @@ class Constants:
     class Common:
         PROJECT_NAME: str = 'we0-index'
 
+    class Log:
+        LEVELS: tuple = ('TRACE', 'DEBUG', 'INFO', 'SUCCESS', 'WARNING', 'ERROR', 'CRITICAL')
+
+    class Header:
+        REQUEST_ID: str = 'X-Request-ID'
+        API_KEY: str = 'X-API-Key'
+
     class Path:
         # SYSTEM PATH
         ROOT_PATH: str = os.path.dirname(os.path.dirname(os.path.abspath(__file__)))
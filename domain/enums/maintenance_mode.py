@@ -0,0 +1,14 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : maintenance_mode
+# @Software: PyCharm
+from enum import StrEnum
+
+
+class MaintenanceMode(StrEnum):
+    OFF = "off"
+    READ_ONLY = "read-only"
+    OFFLINE = "offline"
@@ -0,0 +1,13 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : rate_limit_mode
+# @Software: PyCharm
+from enum import StrEnum
+
+
+class RateLimitMode(StrEnum):
+    ENFORCE = "enforce"
+    SHADOW = "shadow"
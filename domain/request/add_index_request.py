@@ -11,11 +11,11 @@ from pydantic import BaseModel, Field
 
 
 class AddFileInfo(BaseModel):
-    relative_path: str = Field(description='File Relative Path')
+    relative_path: str = Field(min_length=1, description='File Relative Path')
     content: str = Field(description='File Content')
 
 
 class AddIndexRequest(BaseModel):
-    uid: str = Field(description='Unique ID')
-    repo_abs_path: str = Field(description='Repository Absolute Path')
+    uid: str = Field(min_length=1, description='Unique ID')
+    repo_abs_path: str = Field(min_length=1, description='Repository Absolute Path')
     file_infos: List[AddFileInfo]
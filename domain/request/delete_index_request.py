@@ -11,5 +11,5 @@ from pydantic import BaseModel, Field
 
 
 class DeleteIndexRequest(BaseModel):
-    repo_id: str = Field(description='仓库 ID')
+    repo_id: str = Field(min_length=1, description='仓库 ID')
     file_ids: List[str] = Field(description='文件 ID 列表')
@@ -9,4 +9,4 @@ from pydantic import BaseModel, Field
 
 
 class DropIndexRequest(BaseModel):
-    repo_id: str = Field(description='仓库 ID')
+    repo_id: str = Field(min_length=1, description='仓库 ID')
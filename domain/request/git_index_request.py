@@ -1,9 +1,9 @@
-from pydantic import BaseModel
+from pydantic import BaseModel, Field
 from typing import Optional
 
 class GitIndexRequest(BaseModel):
     uid: str | None = None
-    repo_url: str
+    repo_url: str = Field(min_length=1)
     # 私有仓库认证字段
     username: Optional[str] = None  # 用户名
     password: Optional[str] = None  # 密码或个人访问令牌
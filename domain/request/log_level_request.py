@@ -0,0 +1,21 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : log_level_request
+# @Software: PyCharm
+from pydantic import BaseModel, Field, field_validator
+
+from constants.constants import Constants
+
+
+class LogLevelRequest(BaseModel):
+    level: str = Field(description='日志级别: TRACE / DEBUG / INFO / SUCCESS / WARNING / ERROR / CRITICAL')
+
+    @field_validator('level')
+    def check_level(cls, level: str) -> str:
+        level = level.upper()
+        if level not in Constants.Log.LEVELS:
+            raise ValueError(f"level must be one of {', '.join(Constants.Log.LEVELS)}")
+        return level
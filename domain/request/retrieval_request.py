@@ -7,10 +7,10 @@
 # @Software: PyCharm
 from typing import List, Optional
 
-from pydantic import BaseModel
+from pydantic import BaseModel, Field
 
 
 class RetrievalRequest(BaseModel):
-    repo_id: str
+    repo_id: str = Field(min_length=1)
     file_ids: Optional[List[str]] = None
-    query: str
+    query: str = Field(min_length=1)
@@ -0,0 +1,17 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : api_key_response
+# @Software: PyCharm
+from datetime import datetime
+from typing import List
+
+from pydantic import BaseModel, Field
+
+
+class ApiKeyResponse(BaseModel):
+    name: str
+    scopes: List[str]
+    last_used_at: datetime | None = Field(default=None, description='最近一次认证通过的时间，进程启动后未使用过为空')
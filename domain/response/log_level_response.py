@@ -0,0 +1,13 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : log_level_response
+# @Software: PyCharm
+
+from pydantic import BaseModel
+
+
+class LogLevelResponse(BaseModel):
+    level: str
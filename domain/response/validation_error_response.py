@@ -0,0 +1,52 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : validation_error_response
+# @Software: PyCharm
+from typing import Any, Dict, List, Sequence
+
+from pydantic import BaseModel, Field
+
+# FastAPI 错误 loc 的首段为参数来源，其后才是字段路径
+PARAM_LOCATIONS = ('body', 'query', 'path', 'header', 'cookie')
+
+
+class FieldError(BaseModel):
+    field: str = Field(description='出错字段路径，如 file_infos.0.relative_path')
+    message: str = Field(description='错误描述')
+    type: str = Field(description='稳定的错误类型，如 missing、string_too_short，可用于客户端本地化')
+
+    @staticmethod
+    def field_path(loc: Sequence[Any]) -> str:
+        # 仅去掉首段的来源标记，字段本身可能也叫 query、path（如 RetrievalRequest.query）
+        if loc and loc[0] in PARAM_LOCATIONS:
+            loc = loc[1:]
+        return '.'.join(str(part) for part in loc)
+
+    @classmethod
+    def from_errors(cls, errors: Sequence[Dict[str, Any]]) -> List['FieldError']:
+        return [
+            cls(
+                field=cls.field_path(error['loc']),
+                message=error['msg'],
+                type=error['type'],
+            ) for error in errors
+        ]
+
+
+def body_decode_error(errors: Sequence[Dict[str, Any]]) -> str | None:
+    """
+    请求体无法解码（JSON 语法错误、请求体为空）时返回面向调用方的描述，其余情况返回 None
+    这类错误不对应具体字段，按 400 处理；字段校验失败仍按 422 返回 FieldError 列表
+    """
+    for error in errors:
+        loc = tuple(error['loc'])
+        if error['type'] == 'json_invalid':
+            position = loc[1] if len(loc) > 1 else 0
+            reason = (error.get('ctx') or {}).get('error', error['msg'])
+            return f'Malformed JSON body at position {position}: {reason}'
+        if loc == ('body',) and error['type'] == 'missing':
+            return 'Request body is required'
+    return None
@@ -5,8 +5,13 @@
 # @Email   : amashiro2233@gmail.com
 # @File    : result
 # @Software: PyCharm
+from typing import Mapping
 
-from pydantic import BaseModel
+from fastapi.encoders import jsonable_encoder
+from pydantic import BaseModel, Field
+from starlette.responses import JSONResponse
+
+from utils.request_context import get_request_id
 
 
 class Result[T](BaseModel):
@@ -14,11 +19,18 @@ class Result[T](BaseModel):
     message: str
     data: T | None = None
     success: bool
+    request_id: str | None = Field(default_factory=get_request_id)
 
     @classmethod
     def ok(cls, data: T | None = None, code: int = 200, message: str = 'Success'):
         return cls(code=code, message=message, data=data, success=True)
 
     @classmethod
-    def failed(cls, code: int = 500, message: str = 'Internal Server Error'):
-        return cls(code=code, message=message, success=False)
+    def failed(cls, code: int = 500, message: str = 'Internal Server Error', data: T | None = None):
+        return cls(code=code, message=message, data=data, success=False)
+
+    @classmethod
+    def failed_response(cls, status_code: int, message: str, headers: Mapping[str, str] | None = None) -> JSONResponse:
+        """中间件直接拒绝请求时使用，响应体与异常处理器输出的 Result 一致"""
+        error = cls.failed(code=status_code, message=message)
+        return JSONResponse(content=jsonable_encoder(error), status_code=status_code, headers=headers)
@@ -8,6 +8,8 @@
 
 
 class CommonException(Exception):
+    status_code: int = 500
+
     def __init__(self, message=''):
         self.message = message
         super().__init__(self.message)
@@ -21,3 +23,23 @@ class CommonException(Exception):
 
 class StorageUploadFileException(CommonException):
     ...
+
+
+class ValidationException(CommonException):
+    status_code = 422
+
+
+class ForbiddenException(CommonException):
+    status_code = 403
+
+
+class NotFoundException(CommonException):
+    status_code = 404
+
+
+class ConflictException(CommonException):
+    status_code = 409
+
+
+class UpstreamException(CommonException):
+    status_code = 502
@@ -0,0 +1,43 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : handler
+# @Software: PyCharm
+import traceback
+
+from fastapi.encoders import jsonable_encoder
+from loguru import logger
+from starlette.requests import Request
+from starlette.responses import JSONResponse
+
+from constants.constants import Constants
+from domain.result.result import Result
+from exception.reporter import ErrorReporting
+from setting.setting import get_we0_index_settings
+from utils.request_context import get_request_id
+
+
+async def unhandled_exception_response(request: Request, exc: Exception) -> JSONResponse:
+    """
+    未知异常不向调用方暴露内部信息，仅通过 request_id 关联日志；debug 模式下附带堆栈便于本地排查
+    路由抛出的异常由 UnhandledErrorMiddleware 在最内层调用，中间件自身抛出的异常由 ServerErrorMiddleware 兜底调用
+    """
+    request_id = getattr(request.state, 'request_id', None) or get_request_id()
+    data = {
+        'exception': type(exc).__name__,
+        'traceback': traceback.format_exception(exc),
+    } if get_we0_index_settings().server.debug else None
+    error = Result.failed(code=500, message='Internal Server Error', data=data)
+    error.request_id = request_id
+    with logger.contextualize(request_id=request_id or '-'):
+        logger.exception(f"Url: {request.url}, {type(exc).__name__}: {exc} , Error: {error.message}")
+    await ErrorReporting.report(exc, {
+        'request_id': request_id,
+        'method': request.method,
+        'url': str(request.url),
+    })
+    # ServerErrorMiddleware 位于 RequestIdMiddleware 外层，响应头需要在这里补上
+    headers = {Constants.Header.REQUEST_ID: request_id} if request_id else None
+    return JSONResponse(content=jsonable_encoder(error), status_code=500, headers=headers)
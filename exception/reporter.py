@@ -0,0 +1,36 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : reporter
+# @Software: PyCharm
+from abc import ABC, abstractmethod
+from typing import Any, Dict, List
+
+from loguru import logger
+
+
+class ErrorReporter(ABC):
+    """未处理异常的上报接口，可接入 Sentry 等错误追踪服务"""
+
+    @abstractmethod
+    async def report(self, exc: BaseException, context: Dict[str, Any]) -> None:
+        raise NotImplementedError
+
+
+class ErrorReporting:
+    reporters: List[ErrorReporter] = []
+
+    @classmethod
+    def register(cls, reporter: ErrorReporter) -> None:
+        cls.reporters.append(reporter)
+
+    @classmethod
+    async def report(cls, exc: BaseException, context: Dict[str, Any]) -> None:
+        for reporter in cls.reporters:
+            try:
+                await reporter.report(exc, context)
+            except Exception as e:
+                # 上报失败不能影响错误响应本身
+                logger.error(f"Error reporter {type(reporter).__name__} failed: {e}")
@@ -13,9 +13,13 @@ from typing import List, Optional
 from domain.entity.document import Document, DocumentMeta
 from models.model_factory import ModelFactory
 from setting.setting import get_we0_index_settings
+from utils.single_flight import SingleFlight
 
 settings = get_we0_index_settings()
 
+# 获取维度需要真实调用一次 Embedding 接口，合并并发的健康检查与初始化请求
+_dimension_flight = SingleFlight()
+
 
 class BaseVector(ABC):
 
@@ -28,6 +32,16 @@ class BaseVector(ABC):
     async def init(self):
         raise NotImplementedError
 
+    @abstractmethod
+    async def ping(self):
+        raise NotImplementedError
+
+    async def reset(self):
+        """丢弃已有连接，下次访问时重新建立；默认无需处理"""
+
+    async def reload_credentials(self):
+        """凭证轮换后使新凭证生效；默认无需处理"""
+
     @abstractmethod
     async def create(self, documents: List[Document]):
         raise NotImplementedError
@@ -80,6 +94,11 @@ class BaseVector(ABC):
 
     @classmethod
     async def get_dimension(cls) -> int:
+        key = (settings.vector.embedding_provider, settings.vector.embedding_model)
+        return await _dimension_flight.do(key, cls._load_dimension)
+
+    @classmethod
+    async def _load_dimension(cls) -> int:
         embedding_model = await cls.get_embedding_model()
         vector_data_list = await embedding_model.create_embedding(['get_embedding_dimension'])
         dimension = len(vector_data_list[0])
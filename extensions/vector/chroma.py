@@ -54,6 +54,9 @@ class Chroma(BaseVector):
                 logger.error(f"Failed to initialize Chroma: {e}")
                 raise
 
+    async def ping(self):
+        await self._execute_async_or_thread(func=self.client.heartbeat)
+
     async def create(self, documents: List[Document]):
         collection = await self._execute_async_or_thread(
             func=self.client.get_or_create_collection,
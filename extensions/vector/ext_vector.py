@@ -22,6 +22,21 @@ class Vector:
     def __init__(self):
         self.vector_runner: BaseVector | None = None
 
+    async def ping(self):
+        if self.vector_runner is None:
+            raise RuntimeError("Vector clients is not initialized. Call init_app first.")
+        await self.vector_runner.ping()
+
+    async def reset(self):
+        if self.vector_runner is None:
+            raise RuntimeError("Vector clients is not initialized. Call init_app first.")
+        await self.vector_runner.reset()
+
+    async def reload_credentials(self):
+        if self.vector_runner is None:
+            raise RuntimeError("Vector clients is not initialized. Call init_app first.")
+        await self.vector_runner.reload_credentials()
+
     async def create(self, documents: List[Document]):
         try:
             await self.vector_runner.create(documents)
@@ -5,11 +5,16 @@
 # @Email   : amashiro2233@gmail.com
 # @File    : pgvector
 # @Software: PyCharm
+import asyncio
 import json
+import time
 from typing import List, Optional
 
 import numpy as np
-from sqlalchemy import text, bindparam
+from loguru import logger
+from prometheus_client import Counter, Gauge
+from psycopg import errors as pg_errors
+from sqlalchemy import event, text, bindparam
 from sqlalchemy.ext.asyncio import create_async_engine
 
 from domain.entity.document import Document, DocumentMeta
@@ -18,6 +23,16 @@ from setting.setting import get_we0_index_settings
 
 settings = get_we0_index_settings()
 
+DB_POOL_CONNECTIONS = Gauge(
+    'we0_index_pgvector_pool_connections',
+    'PgVector connection pool connections by state (in_use: checked out, idle: checked in)',
+    ['state']
+)
+DB_QUERY_TIMEOUTS = Counter(
+    'we0_index_pgvector_query_timeouts_total',
+    'PgVector statements cancelled by statement_timeout'
+)
+
 SQL_CREATE_FILE_INDEX = lambda table_name: f"""
 CREATE INDEX IF NOT EXISTS file_idx ON {table_name} (file_id);
 """
@@ -47,17 +62,86 @@ class PgVector(BaseVector):
         self.client = self.get_client()
         self.table_name: str | None = None
         self.normalized: bool = False
+        DB_POOL_CONNECTIONS.labels(state='in_use').set_function(lambda: self.client.pool.checkedout())
+        DB_POOL_CONNECTIONS.labels(state='idle').set_function(lambda: self.client.pool.checkedin())
 
     @staticmethod
     def get_client():
         pgvector = settings.vector.pgvector
-        return create_async_engine(
+        connect_args = {'sslmode': pgvector.sslmode}
+        if pgvector.sslrootcert:
+            connect_args['sslrootcert'] = pgvector.sslrootcert
+        if pgvector.query_timeout > 0:
+            # 由数据库侧终止超时语句，调用方无需改动
+            connect_args['options'] = f'-c statement_timeout={int(pgvector.query_timeout * 1000)}'
+        engine = create_async_engine(
             url=f"postgresql+psycopg://{pgvector.user}:{pgvector.password}@{pgvector.host}:{pgvector.port}/{pgvector.db}",
             echo=False,
+            pool_size=pgvector.max_idle_conns,
+            max_overflow=pgvector.max_open_conns - pgvector.max_idle_conns,
+            pool_recycle=pgvector.conn_max_lifetime,
+            pool_pre_ping=True,  # 取出连接前探活，数据库重启后自动替换失效连接
+            connect_args=connect_args,
         )
+        PgVector._watch_queries(engine.sync_engine, pgvector.slow_query_threshold)
+        if pgvector.password_file:
+            @event.listens_for(engine.sync_engine, 'do_connect')
+            def provide_password(dialect, conn_rec, cargs, cparams):
+                # 每个新连接都重新读取密码文件，轮换后无需重建引擎
+                cparams['password'] = pgvector.get_password()
+        return engine
+
+    @staticmethod
+    def _watch_queries(engine, slow_query_threshold: float):
+        @event.listens_for(engine, 'before_cursor_execute')
+        def before_cursor_execute(conn, cursor, statement, parameters, context, executemany):
+            conn.info.setdefault('query_start_time', []).append(time.perf_counter())
+
+        @event.listens_for(engine, 'after_cursor_execute')
+        def after_cursor_execute(conn, cursor, statement, parameters, context, executemany):
+            elapsed = time.perf_counter() - conn.info['query_start_time'].pop()
+            if elapsed >= slow_query_threshold:
+                logger.warning(f"Slow query ({elapsed:.3f}s): {' '.join(statement.split())}")
+
+        @event.listens_for(engine, 'handle_error')
+        def handle_error(context):
+            start_times = context.connection.info.get('query_start_time') if context.connection else None
+            if start_times:
+                start_times.pop()
+            if isinstance(context.original_exception, pg_errors.QueryCanceled):
+                DB_QUERY_TIMEOUTS.inc()
+                logger.error(f"Query cancelled by statement_timeout: {' '.join((context.statement or '').split())}")
+
+    async def ping(self):
+        async with self.client.connect() as conn:
+            await conn.execute(text("SELECT 1"))
+
+    async def reset(self):
+        # dispose 只关闭空闲连接，使用中的连接在归还时丢弃，不会中断进行中的事务
+        await self.client.dispose()
+
+    async def reload_credentials(self):
+        if not settings.vector.pgvector.password_file:
+            return
+        await self.reset()
+        logger.info("PgVector credentials rotated, connections will be re-established with the new password")
+
+    async def _ping_with_retry(self):
+        retries = settings.vector.pgvector.connect_retries
+        for attempt in range(retries + 1):
+            try:
+                await self.ping()
+                return
+            except Exception as e:
+                if attempt == retries:
+                    raise
+                delay = min(2 ** attempt, 30)
+                logger.warning(f"PgVector not reachable ({e}), retrying in {delay}s ({attempt + 1}/{retries})")
+                await asyncio.sleep(delay)
 
     async def init(self):
         try:
+            await self._ping_with_retry()
             async with self.client.begin() as conn:
                 dimension = await self.get_dimension()
                 if dimension > 2000:
@@ -71,8 +155,10 @@ class PgVector(BaseVector):
                 await conn.execute(text(SQL_CREATE_EMBEDDING_INDEX(self.table_name)))
         except Exception as e:
             # Log the error but don't fail completely
-            from loguru import logger
-            logger.error(f"Failed to initialize PgVector: {e}")
+            if settings.vector.pgvector.tls_required:
+                logger.error(f"Failed to initialize PgVector, TLS is required (sslmode={settings.vector.pgvector.sslmode}): {e}")
+            else:
+                logger.error(f"Failed to initialize PgVector: {e}")
             raise
 
     async def _create(self, repo_id: str, documents: List[Document]):
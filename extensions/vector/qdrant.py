@@ -78,6 +78,9 @@ class Qdrant(BaseVector):
             logger.error(f"Failed to initialize Qdrant: {e}")
             raise
 
+    async def ping(self):
+        await self.client.get_collections()
+
     async def create(self, documents: List[Document]):
         repo_id = documents[0].meta.repo_id
         print_structs = []
@@ -7,30 +7,87 @@
 # @Software: PyCharm
 import asyncio
 from contextlib import asynccontextmanager
+from typing import Awaitable, Callable, List, Tuple
 
-from fastapi import FastAPI
+from fastapi import Depends, FastAPI
+from fastapi.exceptions import RequestValidationError
 from fastapi.encoders import jsonable_encoder
+from fastapi.routing import APIRoute
 from loguru import logger
-from starlette.middleware.cors import CORSMiddleware
+from prometheus_client import CONTENT_TYPE_LATEST, generate_latest
+from starlette.middleware.gzip import GZipMiddleware
+from starlette.exceptions import HTTPException as StarletteHTTPException
 from starlette.requests import Request
-from starlette.responses import JSONResponse
+from starlette.responses import JSONResponse, Response
 
 from config.loguru import Log
+from config.reload import install_reload_handler, remove_reload_handler
+from domain.response.validation_error_response import FieldError, body_decode_error
 from domain.result.result import Result
 from exception.exception import CommonException
+from exception.handler import unhandled_exception_response
 from extensions import ext_manager
+from middleware.api_key import ApiKeyMiddleware
+from middleware.body_limit import BodySizeLimitMiddleware
+from middleware.cache_control import CacheControlMiddleware
+from middleware.content_negotiation import MessagePackMiddleware
+from middleware.cors import ReloadableCORSMiddleware
+from middleware.disconnect import DisconnectMiddleware
+from middleware.drain import DrainMiddleware
+from middleware.load_shed import LoadShedMiddleware
+from middleware.maintenance import MaintenanceMiddleware
+from middleware.metrics import MetricsMiddleware
+from middleware.rate_limit import RateLimitMiddleware
+from middleware.request_id import RequestIdMiddleware
+from middleware.timeout import TimeoutMiddleware
+from middleware.tracing import TracingMiddleware, setup_tracing
+from middleware.unhandled_error import UnhandledErrorMiddleware
+from router.admin_router import admin_router
 from router.git_router import git_router
 from router.vector_router import vector_router
+from router.versioning import API_VERSIONS, create_version_router, deprecated
 from setting.setting import get_we0_index_settings
-from utils.health_check import comprehensive_health_check
+from utils.health_check import ReadinessState, comprehensive_health_check, monitor_vector_database, readiness_check
 
 settings = get_we0_index_settings()
 
 
+# (名称, 初始化函数, 是否必需)；可选依赖初始化失败只记录告警，不阻止启动
+STARTUP_DEPENDENCIES: List[Tuple[str, Callable[[], Awaitable[None]], bool]] = [
+    ('vector', ext_manager.init_vector, True),
+]
+
+
+async def _initialize_dependency(name: str, init: Callable[[], Awaitable[None]]) -> Exception | None:
+    timeout = settings.server.startup.get_timeout(name)
+    try:
+        await asyncio.wait_for(init(), timeout=timeout)
+    except asyncio.TimeoutError:
+        return TimeoutError(f"not ready within {timeout}s")
+    except Exception as e:
+        return e
+    return None
+
+
 async def initialize_extensions():
-    await asyncio.gather(
-        ext_manager.init_vector(),
-    )
+    """并行初始化各依赖，每项有独立时限，整体受 startup.timeout 约束；任一必需依赖失败则汇总报错并终止启动"""
+    try:
+        errors = await asyncio.wait_for(
+            asyncio.gather(*(_initialize_dependency(name, init) for name, init, _ in STARTUP_DEPENDENCIES)),
+            timeout=settings.server.startup.timeout,
+        )
+    except asyncio.TimeoutError:
+        raise RuntimeError(f"Startup did not complete within {settings.server.startup.timeout}s")
+    failures = []
+    for (name, _, required), error in zip(STARTUP_DEPENDENCIES, errors):
+        if error is None:
+            continue
+        if required:
+            failures.append(f"{name}: {type(error).__name__}: {error}")
+        else:
+            logger.warning(f"Optional dependency '{name}' unavailable, continuing without it: {error}")
+    if failures:
+        raise RuntimeError("Required dependencies failed to start:\n" + "\n".join(f"  - {failure}" for failure in failures))
 
 
 async def close_extensions():
@@ -39,11 +96,20 @@ async def close_extensions():
 
 @asynccontextmanager
 async def lifespan(fastapi: FastAPI):
+    monitor: asyncio.Task | None = None
     try:
         Log.start()
         await initialize_extensions()
+        install_reload_handler()
+        if settings.vector.health_check_interval > 0:
+            monitor = asyncio.create_task(monitor_vector_database(settings.vector.health_check_interval))
+        ReadinessState.ready = True
         yield
     finally:
+        ReadinessState.ready = False
+        if monitor is not None:
+            monitor.cancel()
+        remove_reload_handler()
         await close_extensions()
         Log.close()
 
@@ -53,25 +119,98 @@ def create_app() -> FastAPI:
         title="We0 Index",
         description="We0 Index API",
         version="0.1.0",
-        lifespan=lifespan
+        lifespan=lifespan,
+        # 生产环境可通过 server.docs 关闭 /docs、/redoc 与 /openapi.json
+        docs_url="/docs" if settings.server.docs else None,
+        redoc_url="/redoc" if settings.server.docs else None,
+        openapi_url="/openapi.json" if settings.server.docs else None,
     )
 
-    # 添加 CORS 中间件
+    # 位于最内层，路由抛出的未处理异常在这里转换为 500 响应，外层中间件照常记录并附带响应头
+    app.add_middleware(UnhandledErrorMiddleware)
+    # 客户端断开时只取消路由处理，外层中间件照常记录结果
+    app.add_middleware(DisconnectMiddleware)
+    # 位于 GZip 内层，MessagePack 编码后的响应体仍可被压缩
+    app.add_middleware(MessagePackMiddleware)
+    # 位于 MessagePack 外层、GZip 内层，ETag 按协商后的未压缩响应体计算
+    app.add_middleware(CacheControlMiddleware, routes=settings.server.cache_control.routes)
+    if settings.server.compression.enabled:
+        # 检索结果与索引元数据体积较大，超过阈值时按 Accept-Encoding 进行 gzip 压缩
+        app.add_middleware(
+            GZipMiddleware,
+            minimum_size=settings.server.compression.minimum_size,
+            compresslevel=settings.server.compression.level,
+        )
     app.add_middleware(
-        CORSMiddleware,
-        allow_origins=["*"],
-        allow_credentials=True,
-        allow_methods=["*"],
-        allow_headers=["*"],
+        BodySizeLimitMiddleware,
+        default=settings.server.body_limit.default,
+        routes=settings.server.body_limit.routes,
     )
+    app.add_middleware(
+        TimeoutMiddleware,
+        default=settings.server.timeout.default,
+        routes=settings.server.timeout.routes,
+    )
+    app.add_middleware(MaintenanceMiddleware)
+    app.add_middleware(RateLimitMiddleware)
+    if settings.auth.api_keys:
+        # 位于限流外层，限流可按认证后的 API Key 选择限额
+        app.add_middleware(ApiKeyMiddleware)
+    if settings.server.load_shed.max_in_flight > 0:
+        app.add_middleware(
+            LoadShedMiddleware,
+            max_in_flight=settings.server.load_shed.max_in_flight,
+            retry_after=settings.server.load_shed.retry_after,
+            exempt=settings.server.load_shed.exempt,
+        )
+    app.add_middleware(MetricsMiddleware)
+    if settings.tracing.enabled:
+        setup_tracing(settings.tracing)
+        app.add_middleware(TracingMiddleware)
+    app.add_middleware(DrainMiddleware)
+    # 位于鉴权、限流、维护模式等中间件外层：预检请求直接由 CORS 响应，拒绝请求的 4xx/5xx 也带有 CORS 响应头，浏览器可读取
+    app.add_middleware(ReloadableCORSMiddleware)
+    app.add_middleware(RequestIdMiddleware)
 
     return app
 
 
 app = create_app()
 # 注册路由
-app.include_router(vector_router, prefix="/vector", tags=["vector"])
-app.include_router(git_router, prefix="/git", tags=["git"])
+v1_router = create_version_router('v1')
+v1_router.include_router(vector_router, prefix="/vector", tags=["vector"])
+v1_router.include_router(git_router, prefix="/git", tags=["git"])
+app.include_router(v1_router)
+# 无版本前缀的旧路径保留为 v1 的别名，响应附带弃用相关的响应头
+legacy_dependencies = [Depends(deprecated(successor='/v1', sunset=settings.server.legacy_sunset))]
+app.include_router(vector_router, prefix="/vector", tags=["vector"], deprecated=True, dependencies=legacy_dependencies)
+app.include_router(git_router, prefix="/git", tags=["git"], deprecated=True, dependencies=legacy_dependencies)
+if settings.auth.api_keys:
+    # 管理接口仅在启用 API Key 鉴权时开放，可通过 scopes 限定为 /admin
+    app.include_router(admin_router, prefix="/admin", tags=["admin"])
+
+
+@app.api_route(
+    "/v{version:int}/{path:path}",
+    methods=["GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"],
+    include_in_schema=False,
+)
+async def unknown_api_version(request: Request, version: int, path: str):
+    if f"v{version}" in API_VERSIONS:
+        # catch-all 匹配所有方法，会抢在路径存在但方法不符的路由之前，这里还原 405
+        allowed = sorted({
+            method for route in app.routes
+            if isinstance(route, APIRoute) and route.endpoint is not unknown_api_version
+            and route.path_regex.match(request.url.path)
+            for method in route.methods
+        })
+        if allowed:
+            raise StarletteHTTPException(status_code=405, headers={'Allow': ', '.join(allowed)})
+        raise StarletteHTTPException(status_code=404)
+    raise StarletteHTTPException(
+        status_code=404,
+        detail=f"Unknown API version v{version}, supported versions: {', '.join(API_VERSIONS)}"
+    )
 
 
 @app.get("/health", tags=["health"])
@@ -89,18 +228,63 @@ async def health_check():
         )
 
 
+@app.get("/healthz", tags=["health"])
+async def liveness():
+    """Liveness probe, only reports that the process is up"""
+    return {"status": "ok"}
+
+
+@app.get("/readyz", tags=["health"])
+async def readiness():
+    """Readiness probe, checks dependencies needed to serve requests"""
+    readiness_status = await readiness_check()
+    status_code = 200 if readiness_status["ready"] else 503
+    return JSONResponse(content=readiness_status, status_code=status_code)
+
+
+@app.get("/metrics", tags=["health"], include_in_schema=False)
+async def metrics():
+    """Prometheus metrics endpoint"""
+    return Response(content=generate_latest(), media_type=CONTENT_TYPE_LATEST)
+
+
+@app.exception_handler(RequestValidationError)
+async def validation_exception_handler(request: Request, exc: RequestValidationError):
+    decode_error = body_decode_error(exc.errors())
+    if decode_error:
+        error = Result.failed(code=400, message=decode_error)
+        error.request_id = getattr(request.state, 'request_id', None)
+        logger.warning(f"Url: {request.url}, Bad Request: {decode_error}")
+        return JSONResponse(content=jsonable_encoder(error), status_code=400)
+    error = Result.failed(code=422, message='Validation Failed', data=FieldError.from_errors(exc.errors()))
+    error.request_id = getattr(request.state, 'request_id', None)
+    logger.warning(f"Url: {request.url}, Validation Failed: {error.data}")
+    return JSONResponse(content=jsonable_encoder(error), status_code=422)
+
+
+@app.exception_handler(StarletteHTTPException)
+async def http_exception_handler(request: Request, exc: StarletteHTTPException):
+    error = Result.failed(code=exc.status_code, message=str(exc.detail))
+    error.request_id = getattr(request.state, 'request_id', None)
+    return JSONResponse(content=jsonable_encoder(error), status_code=exc.status_code, headers=exc.headers)
+
+
 @app.exception_handler(CommonException)
 async def common_exception_handler(request: Request, exc: CommonException):
-    error = Result.failed(code=-1, message=exc.message)
-    logger.exception(f"Url: {request.url}, Exception: CommonException, Error: {error}")
-    return JSONResponse(content=jsonable_encoder(error))
+    error = Result.failed(code=exc.status_code, message=exc.message)
+    error.request_id = getattr(request.state, 'request_id', None)
+    if exc.status_code < 500:
+        # 4xx 为调用方的问题，不输出堆栈
+        logger.warning(f"Url: {request.url}, Exception: {type(exc).__name__}, Error: {error}")
+    else:
+        logger.exception(f"Url: {request.url}, Exception: {type(exc).__name__}, Error: {error}")
+    return JSONResponse(content=jsonable_encoder(error), status_code=exc.status_code)
 
 
 @app.exception_handler(Exception)
 async def exception_handler(request: Request, exc: Exception):
-    error = Result.failed(code=-1, message=str(exc.args))
-    logger.exception(f"Url: {request.url}, {type(exc).__name__}: {exc} , Error: {error}")
-    return JSONResponse(content=jsonable_encoder(error))
+    # 兜底中间件自身抛出的异常，路由抛出的异常已由 UnhandledErrorMiddleware 处理
+    return await unhandled_exception_response(request, exc)
 
 
 # CORS middleware already added in create_app function
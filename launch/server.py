@@ -0,0 +1,44 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : server
+# @Software: PyCharm
+import threading
+from types import FrameType
+
+import uvicorn
+from loguru import logger
+
+from utils.health_check import ReadinessState
+
+
+class GracefulServer(uvicorn.Server):
+    """
+    收到退出信号后先将 /readyz 置为未就绪并进入排空状态（新请求返回 503），等待 shutdown_delay 秒让负载均衡摘除流量，
+    再交由 uvicorn 停止监听并等待处理中的请求完成
+    等待期间再次收到信号则跳过剩余等待，立即开始停止；停止过程中再次收到 SIGINT 由 uvicorn 强制退出
+    """
+
+    def __init__(self, config: uvicorn.Config, shutdown_delay: float):
+        super().__init__(config)
+        self.shutdown_delay = shutdown_delay
+        self.delay_timer: threading.Timer | None = None
+
+    def handle_exit(self, sig: int, frame: FrameType | None) -> None:
+        ReadinessState.ready = False
+        ReadinessState.draining = True
+        if self.delay_timer is not None:
+            # 取消计时器，否则计时结束时的二次调用会被 uvicorn 视为强制退出，中断仍在处理的请求
+            self.delay_timer.cancel()
+            self.delay_timer = None
+            super().handle_exit(sig, frame)
+            return
+        if self.should_exit or self.shutdown_delay <= 0:
+            super().handle_exit(sig, frame)
+            return
+        logger.info(f"Received signal {sig}, shutting down in {self.shutdown_delay}s")
+        self.delay_timer = threading.Timer(self.shutdown_delay, super().handle_exit, args=(sig, frame))
+        self.delay_timer.daemon = True
+        self.delay_timer.start()
@@ -5,18 +5,24 @@
 # @Email   : amashiro2233@gmail.com
 # @File    : we0_index_mcp
 # @Software: PyCharm
+import functools
+import uuid
 from contextlib import asynccontextmanager
-from typing import AsyncIterator
+from typing import AsyncIterator, Awaitable, Callable
 
+from loguru import logger
 from mcp.server.fastmcp import FastMCP
 from mcp.server.fastmcp.tools import Tool
 from mcp.server.lowlevel.server import LifespanResultT, Server
 from mcp.shared.context import RequestT
 
+from domain.result.result import Result
+from exception.exception import CommonException
 from extensions import ext_manager
 from router.git_router import clone_and_index
 from router.vector_router import retrieval
 from setting.setting import get_we0_index_settings
+from utils.request_context import request_id_context
 
 sider_settings = get_we0_index_settings()
 
@@ -30,13 +36,39 @@ async def lifespan(server: Server[LifespanResultT, RequestT]) -> AsyncIterator[o
     # For now, no specific cleanup is required
 
 
+def tool_result(fn: Callable[..., Awaitable[Result]]) -> Callable[..., Awaitable[Result]]:
+    """
+    HTTP 路由通过异常返回对应的状态码，MCP 工具没有状态码，将异常转换为 Result.failed 返回给调用方
+    每次调用生成 request_id 用于关联日志；只有 CommonException 的信息返回给调用方，未知异常仅返回通用信息
+    """
+
+    @functools.wraps(fn)
+    async def wrapper(*args, **kwargs) -> Result:
+        request_id = uuid.uuid4().hex
+        token = request_id_context.set(request_id)
+        try:
+            with logger.contextualize(request_id=request_id):
+                try:
+                    return await fn(*args, **kwargs)
+                except CommonException as e:
+                    logger.warning(f"{type(e).__name__}: {e}")
+                    return Result.failed(code=e.status_code, message=e.message)
+                except Exception as e:
+                    logger.exception(f"Tool: {fn.__name__}, {type(e).__name__}: {e}")
+                    return Result.failed(code=500, message='Internal Server Error')
+        finally:
+            request_id_context.reset(token)
+
+    return wrapper
+
+
 def create_fast_mcp() -> FastMCP:
     app = FastMCP(
         name="We0 Index",
         description="CodeIndex, embedding, retrieval, Tool parameters must be in standard JSON format",
         tools=[
-            Tool.from_function(clone_and_index),
-            Tool.from_function(retrieval),
+            Tool.from_function(tool_result(clone_and_index)),
+            Tool.from_function(tool_result(retrieval)),
         ],
         lifespan=lifespan,
         host=sider_settings.server.host,
@@ -6,29 +6,53 @@
 # @File    : main
 # @Software: PyCharm
 
+import ssl
+import sys
+
 import click
 import uvicorn
 
+from config.validation import ConfigurationError, load_settings
 from constants.constants import Constants
-from launch.we0_index_mcp import we0_index_mcp
-from setting.setting import get_we0_index_settings
 
 @click.command()
 @click.option('--mode', default='mcp', show_default=True, type=click.Choice(['mcp', 'fastapi']), required=True, help='Choose run mode: "mcp" or "fastapi".')
 @click.option('--transport', default='streamable-http', show_default=True, type=click.Choice(['streamable-http', 'stdio', 'sse']), help='Transport protocol for MCP mode')
 def main(mode, transport):
-    sider_settings = get_we0_index_settings()
+    # 先校验配置再导入应用模块，避免在连接数据库等阶段才暴露配置错误
+    try:
+        sider_settings = load_settings()
+    except ConfigurationError as e:
+        click.echo(str(e), err=True)
+        sys.exit(1)
 
     if mode == 'mcp':
+        from launch.we0_index_mcp import we0_index_mcp
         we0_index_mcp.run(transport)
     elif mode == 'fastapi':
-        uvicorn.run(
-            'launch.launch:app',
+        tls = sider_settings.server.tls
+        ssl_options = dict(
+            ssl_certfile=tls.cert,
+            ssl_keyfile=tls.key,
+            ssl_version=ssl.PROTOCOL_TLS_SERVER,
+            ssl_ciphers=tls.ciphers,
+        ) if tls.enabled else {}
+        options = dict(
             host=sider_settings.server.host,
             port=sider_settings.server.port,
             reload=sider_settings.server.reload,
-            env_file=Constants.Path.ENV_FILE_PATH
+            env_file=Constants.Path.ENV_FILE_PATH,
+            **ssl_options
         )
+        if sider_settings.server.forwarded_allow_ips:
+            options.update(proxy_headers=True, forwarded_allow_ips=sider_settings.server.forwarded_allow_ips)
+        if sider_settings.server.reload:
+            # 热重载模式下由 uvicorn 管理子进程，不做退出前摘流
+            uvicorn.run('launch.launch:app', **options)
+        else:
+            from launch.server import GracefulServer
+            config = uvicorn.Config('launch.launch:app', **options)
+            GracefulServer(config, shutdown_delay=sider_settings.server.shutdown_delay).run()
     else:
         raise ValueError(f"Unknown mode: {mode}")
 
@@ -0,0 +1,68 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : api_key
+# @Software: PyCharm
+import hmac
+import time
+from typing import Dict
+
+from loguru import logger
+from prometheus_client import Gauge
+from starlette.middleware.base import BaseHTTPMiddleware, RequestResponseEndpoint
+from starlette.requests import Request
+from starlette.responses import Response
+
+from constants.constants import Constants
+from domain.result.result import Result
+from setting.setting import ApiKeySettings, get_we0_index_settings
+from utils.helper import Helper
+from utils.path_match import match_path_prefix
+
+API_KEY_LAST_USED = Gauge(
+    'we0_index_api_key_last_used_timestamp_seconds',
+    'Unix time of the last authenticated request per API key',
+    ['key']
+)
+
+# API Key 名称 -> 最近一次认证通过的 Unix 时间，供 /admin/api-keys 审计；key 只存在于配置中，记录随进程重启清空
+api_key_last_used: Dict[str, float] = {}
+
+
+class ApiKeyMiddleware(BaseHTTPMiddleware):
+    """
+    服务间调用的 API Key 认证，配置中只保存 key 的 SHA-256 摘要
+    scopes 为允许访问的路径前缀，'*' 表示不限制
+    key 列表每次请求从当前配置快照读取，吊销或新增 key 后发送 SIGHUP 即可生效
+    认证通过时记录 key 的最近使用时间（api_key_last_used 与 we0_index_api_key_last_used_timestamp_seconds）
+    """
+
+    @staticmethod
+    def authenticate(api_key: str) -> ApiKeySettings | None:
+        digest = Helper.generate_text_hash(api_key)
+        for key in get_we0_index_settings().auth.api_keys:
+            if hmac.compare_digest(digest, key.hash.lower()):
+                return key
+        return None
+
+    async def dispatch(self, request: Request, call_next: RequestResponseEndpoint) -> Response:
+        path = request.url.path
+        exempt = get_we0_index_settings().auth.exempt
+        if request.method == 'OPTIONS' or match_path_prefix(path, exempt):
+            return await call_next(request)
+        api_key = request.headers.get(Constants.Header.API_KEY)
+        if not api_key:
+            return Result.failed_response(401, 'Missing API key')
+        key = self.authenticate(api_key)
+        if key is None:
+            return Result.failed_response(401, 'Invalid API key')
+        if '*' not in key.scopes and not match_path_prefix(path, key.scopes):
+            return Result.failed_response(403, f'API key "{key.name}" is not allowed to access {path}')
+        logger.debug(f'Authenticated API key "{key.name}"')
+        now = time.time()
+        api_key_last_used[key.name] = now
+        API_KEY_LAST_USED.labels(key=key.name).set(now)
+        request.state.api_key = key.name
+        return await call_next(request)
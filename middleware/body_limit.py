@@ -0,0 +1,77 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : body_limit
+# @Software: PyCharm
+from typing import Dict
+
+from starlette.datastructures import Headers
+from starlette.exceptions import HTTPException
+from starlette.types import ASGIApp, Message, Receive, Scope, Send
+
+from domain.result.result import Result
+from router.versioning import unversioned_path
+
+
+class BodySizeLimitMiddleware:
+    """
+    限制请求体大小，超限返回 413
+    声明了 Content-Length 的请求直接拒绝；分块传输的请求在读取过程中累计字节数，
+    超限时抛出 HTTPException：由路由读取请求体时经异常处理器输出 JSON 响应，
+    由外层中间件（如 MessagePack 解码）读取时异常不经过异常处理器，在这里直接返回 413
+    routes 以路径前缀覆盖默认限制，最长前缀优先；限制 <= 0 表示不限制
+    """
+
+    def __init__(self, app: ASGIApp, default: int, routes: Dict[str, int] | None = None):
+        self.app = app
+        self.default = default
+        self.routes = sorted((routes or {}).items(), key=lambda item: len(item[0]), reverse=True)
+
+    def get_limit(self, path: str) -> int:
+        for prefix, limit in self.routes:
+            if path.startswith(prefix):
+                return limit
+        return self.default
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send) -> None:
+        if scope['type'] != 'http':
+            await self.app(scope, receive, send)
+            return
+        limit = self.get_limit(unversioned_path(scope['path']))
+        if limit <= 0:
+            await self.app(scope, receive, send)
+            return
+
+        content_length = Headers(scope=scope).get('content-length')
+        if content_length and content_length.isdigit() and int(content_length) > limit:
+            response = Result.failed_response(413, f'Request body exceeds {limit} bytes')
+            await response(scope, receive, send)
+            return
+
+        received = 0
+        response_started = False
+
+        async def limited_receive() -> Message:
+            nonlocal received
+            message = await receive()
+            if message['type'] == 'http.request':
+                received += len(message.get('body', b''))
+                if received > limit:
+                    # FastAPI 解析请求体时只透传 HTTPException，其余异常会被转换为 400
+                    raise HTTPException(status_code=413, detail=f'Request body exceeds {limit} bytes')
+            return message
+
+        async def tracked_send(message: Message) -> None:
+            nonlocal response_started
+            if message['type'] == 'http.response.start':
+                response_started = True
+            await send(message)
+
+        try:
+            await self.app(scope, limited_receive, tracked_send)
+        except HTTPException as e:
+            if e.status_code != 413 or received <= limit or response_started:
+                raise
+            await Result.failed_response(413, str(e.detail))(scope, receive, send)
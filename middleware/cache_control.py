@@ -0,0 +1,83 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : cache_control
+# @Software: PyCharm
+import hashlib
+import time
+from datetime import timezone
+from email.utils import formatdate, parsedate_to_datetime
+from typing import Dict, Tuple
+
+from starlette.middleware.base import BaseHTTPMiddleware, RequestResponseEndpoint
+from starlette.requests import Request
+from starlette.responses import Response
+from starlette.types import ASGIApp
+
+from router.versioning import unversioned_path
+
+NO_STORE = 'private, no-store'
+VARY = 'Accept, Accept-Encoding'
+
+
+class CacheControlMiddleware(BaseHTTPMiddleware):
+    """
+    routes 中声明的 GET 接口可被 CDN 与浏览器缓存：附带 Cache-Control: public, max-age、ETag 与 Last-Modified，
+    If-None-Match（未携带时为 If-Modified-Since）命中时返回 304；其余响应（索引、检索结果等）一律标记为 private, no-store
+    已自行设置 Cache-Control 的响应保持不变；所有响应都附带 Vary: Accept, Accept-Encoding，避免 CDN 混用 JSON 与 MessagePack、压缩与未压缩的表示
+    位于 MessagePack 外层、GZip 内层，ETag 按协商后的未压缩响应体计算，不同表示的 ETag 互不相同
+    这些接口的响应由进程动态生成，Last-Modified 取本进程内首次观察到当前 ETag 的时间
+    """
+
+    def __init__(self, app: ASGIApp, routes: Dict[str, int] | None = None):
+        super().__init__(app)
+        self.routes = routes or {}
+        # (path, content-type) -> (etag, last_modified 秒级时间戳)
+        self.versions: Dict[Tuple[str, str], Tuple[str, int]] = {}
+
+    def get_last_modified(self, key: Tuple[str, str], etag: str) -> int:
+        version = self.versions.get(key)
+        if version is None or version[0] != etag:
+            version = self.versions[key] = (etag, int(time.time()))
+        return version[1]
+
+    @staticmethod
+    def is_not_modified(request: Request, etag: str, last_modified: int) -> bool:
+        if_none_match = request.headers.get('if-none-match')
+        if if_none_match is not None:
+            return etag in [tag.strip() for tag in if_none_match.split(',')]
+        if_modified_since = request.headers.get('if-modified-since')
+        if not if_modified_since:
+            return False
+        try:
+            since = parsedate_to_datetime(if_modified_since)
+        except (TypeError, ValueError):
+            return False
+        if since.tzinfo is None:
+            since = since.replace(tzinfo=timezone.utc)
+        return last_modified <= since.timestamp()
+
+    async def dispatch(self, request: Request, call_next: RequestResponseEndpoint) -> Response:
+        response = await call_next(request)
+        response.headers.add_vary_header(VARY)
+        if 'cache-control' in response.headers:
+            return response
+        path = unversioned_path(request.url.path)
+        max_age = self.routes.get(path)
+        if max_age is None or request.method not in ('GET', 'HEAD') or response.status_code != 200:
+            response.headers['Cache-Control'] = NO_STORE
+            return response
+
+        body = b''.join([chunk async for chunk in response.body_iterator])
+        etag = f'W/"{hashlib.sha256(body).hexdigest()[:32]}"'
+        headers = dict(response.headers)
+        headers['Cache-Control'] = f'public, max-age={max_age}'
+        headers['ETag'] = etag
+        last_modified = self.get_last_modified((path, response.headers.get('content-type', '')), etag)
+        headers['Last-Modified'] = formatdate(last_modified, usegmt=True)
+        if self.is_not_modified(request, etag, last_modified):
+            headers.pop('content-length', None)
+            return Response(status_code=304, headers=headers)
+        return Response(content=body, status_code=response.status_code, headers=headers)
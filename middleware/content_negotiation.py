@@ -0,0 +1,130 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : content_negotiation
+# @Software: PyCharm
+import json
+
+import msgpack
+from starlette.datastructures import Headers, MutableHeaders
+from starlette.types import ASGIApp, Message, Receive, Scope, Send
+
+from domain.result.result import Result
+
+MSGPACK_MEDIA_TYPES = ('application/msgpack', 'application/x-msgpack')
+JSON_MEDIA_TYPE = 'application/json'
+
+
+def prefers_msgpack(accept: str | None) -> bool:
+    """
+    按 Accept 的 q 值协商响应格式，未声明或无法识别时返回 False（默认 JSON）
+    """
+    if not accept:
+        return False
+    msgpack_q, json_q = 0.0, 0.0
+    for item in accept.split(','):
+        media_type, *params = [part.strip() for part in item.split(';')]
+        q = 1.0
+        for param in params:
+            if param.startswith('q='):
+                try:
+                    q = float(param[2:])
+                except ValueError:
+                    q = 0.0
+        if media_type.lower() in MSGPACK_MEDIA_TYPES:
+            msgpack_q = max(msgpack_q, q)
+        elif media_type.lower() in (JSON_MEDIA_TYPE, 'application/*', '*/*'):
+            json_q = max(json_q, q)
+    return msgpack_q > 0 and msgpack_q >= json_q
+
+
+class MessagePackMiddleware:
+    """
+    支持 MessagePack 的内容协商，路由仍只处理 JSON：
+    Content-Type 为 MessagePack 的请求体先转为 JSON 再交给路由；
+    Accept 偏好 MessagePack 时将 JSON 响应编码为 MessagePack，其余响应（文件、流式等）原样透传
+    """
+
+    def __init__(self, app: ASGIApp):
+        self.app = app
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send) -> None:
+        if scope['type'] != 'http':
+            await self.app(scope, receive, send)
+            return
+        headers = Headers(scope=scope)
+        content_type = headers.get('content-type', '').split(';')[0].strip().lower()
+        if content_type in MSGPACK_MEDIA_TYPES:
+            decoded = await self.decode_request(scope, receive)
+            if decoded is None:
+                await Result.failed_response(400, 'Malformed MessagePack body')(scope, receive, send)
+                return
+            receive = decoded
+        if prefers_msgpack(headers.get('accept')):
+            send = self.encode_response(send)
+        await self.app(scope, receive, send)
+
+    @staticmethod
+    async def decode_request(scope: Scope, receive: Receive) -> Receive | None:
+        """
+        直接修改传入的 scope：路由匹配写入的 route 等信息需要对外层的指标、追踪中间件可见，不能替换为副本
+        """
+        body = b''
+        more_body = True
+        while more_body:
+            message = await receive()
+            if message['type'] != 'http.request':
+                return None
+            body += message.get('body', b'')
+            more_body = message.get('more_body', False)
+        try:
+            body = json.dumps(msgpack.unpackb(body, raw=False)).encode()
+        except (ValueError, TypeError, msgpack.UnpackException):
+            return None
+
+        headers = MutableHeaders(scope=scope)
+        headers['content-type'] = JSON_MEDIA_TYPE
+        headers['content-length'] = str(len(body))
+        consumed = False
+
+        async def replay_receive() -> Message:
+            nonlocal consumed
+            if not consumed:
+                consumed = True
+                return {'type': 'http.request', 'body': body, 'more_body': False}
+            return await receive()
+
+        return replay_receive
+
+    @staticmethod
+    def encode_response(send: Send) -> Send:
+        start: Message | None = None
+        body = b''
+
+        async def msgpack_send(message: Message) -> None:
+            nonlocal start, body
+            if message['type'] == 'http.response.start':
+                content_type = Headers(raw=message['headers']).get('content-type', '')
+                # 204/304 没有响应体，头部原样透传
+                if message['status'] not in (204, 304) and content_type.split(';')[0].strip().lower() == JSON_MEDIA_TYPE:
+                    start = message
+                    return
+                await send(message)
+                return
+            if message['type'] != 'http.response.body' or start is None:
+                await send(message)
+                return
+            body += message.get('body', b'')
+            if message.get('more_body', False):
+                return
+            packed = msgpack.packb(json.loads(body), use_bin_type=True) if body else b''
+            headers = MutableHeaders(raw=start['headers'])
+            headers['content-type'] = MSGPACK_MEDIA_TYPES[0]
+            headers['content-length'] = str(len(packed))
+            headers.add_vary_header('Accept')
+            await send(start)
+            await send({'type': 'http.response.body', 'body': packed, 'more_body': False})
+
+        return msgpack_send
@@ -0,0 +1,72 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : cors
+# @Software: PyCharm
+import re
+from typing import Any, Dict, List, Tuple
+
+from starlette.middleware.cors import CORSMiddleware
+from starlette.types import ASGIApp, Receive, Scope, Send
+
+from router.versioning import unversioned_path
+from setting.setting import CorsPolicySettings, CorsSettings, get_we0_index_settings
+
+_SUBDOMAIN_PATTERN = r'[a-zA-Z0-9-]+(?:\.[a-zA-Z0-9-]+)*'
+
+
+def build_origin_regex(origins: List[str]) -> str | None:
+    """将 https://*.example.com 形式的通配子域名转换为 CORSMiddleware 可用的正则"""
+    patterns = [
+        re.escape(origin).replace(r'\*', _SUBDOMAIN_PATTERN)
+        for origin in origins if origin != '*' and '*' in origin
+    ]
+    return '|'.join(f'(?:{pattern})' for pattern in patterns) or None
+
+
+def build_cors_options(cors: CorsPolicySettings) -> Dict[str, Any]:
+    return {
+        'allow_origins': [origin for origin in cors.allowed_origins if origin == '*' or '*' not in origin],
+        'allow_origin_regex': build_origin_regex(cors.allowed_origins),
+        'allow_methods': cors.allow_methods,
+        'allow_headers': cors.allow_headers,
+        'allow_credentials': cors.allow_credentials,
+        'expose_headers': cors.expose_headers,
+        'max_age': cors.max_age,
+    }
+
+
+class ReloadableCORSMiddleware:
+    """
+    按当前配置快照构建 CORSMiddleware，配置热更新后自动重建
+    routes 中的路径前缀各自使用独立策略，预检响应按各自的 max-age 缓存
+    """
+
+    def __init__(self, app: ASGIApp):
+        self.app = app
+        self.cors: CorsSettings | None = None
+        self.default: CORSMiddleware | None = None
+        self.routes: List[Tuple[str, CORSMiddleware]] = []
+
+    def get_middleware(self, path: str) -> CORSMiddleware:
+        cors = get_we0_index_settings().cors
+        if cors is not self.cors:
+            self.default = CORSMiddleware(self.app, **build_cors_options(cors))
+            self.routes = sorted(
+                ((prefix, CORSMiddleware(self.app, **build_cors_options(policy))) for prefix, policy in cors.routes.items()),
+                key=lambda item: len(item[0]),
+                reverse=True
+            )
+            self.cors = cors
+        for prefix, middleware in self.routes:
+            if path.startswith(prefix):
+                return middleware
+        return self.default
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send) -> None:
+        if scope['type'] != 'http':
+            await self.app(scope, receive, send)
+            return
+        await self.get_middleware(unversioned_path(scope['path']))(scope, receive, send)
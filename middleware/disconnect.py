@@ -0,0 +1,83 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : disconnect
+# @Software: PyCharm
+import asyncio
+
+from loguru import logger
+from prometheus_client import Counter
+from starlette.types import ASGIApp, Message, Receive, Scope, Send
+
+from domain.result.result import Result
+
+REQUESTS_CANCELLED = Counter(
+    'we0_index_http_requests_cancelled_total',
+    'HTTP requests cancelled because the client disconnected'
+)
+
+# nginx 约定的 "Client Closed Request"，客户端已断开，仅供外层中间件与指标记录
+CLIENT_CLOSED_REQUEST = 499
+
+
+class DisconnectMiddleware:
+    """
+    客户端断开连接后取消仍在处理的请求，正在执行的数据库、向量库与模型调用随协程取消而中断，
+    与 TimeoutMiddleware 配合释放资源；经 SingleFlight 合并的调用除外，见 SingleFlight
+    请求体读取完毕后才开始监听断开事件，不与路由读取请求体相互竞争
+    """
+
+    def __init__(self, app: ASGIApp):
+        self.app = app
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send) -> None:
+        if scope['type'] != 'http':
+            await self.app(scope, receive, send)
+            return
+
+        app_task: asyncio.Task | None = None
+        watcher: asyncio.Task | None = None
+        disconnected = False
+        response_started = False
+
+        async def watch_disconnect() -> Message:
+            nonlocal disconnected
+            message = await receive()
+            if message['type'] == 'http.disconnect' and app_task is not None and not app_task.done():
+                disconnected = True
+                app_task.cancel()
+            return message
+
+        async def watched_receive() -> Message:
+            nonlocal watcher
+            if watcher is not None:
+                # 请求体已读完，后续的 receive 复用监听任务拿到的消息
+                return await asyncio.shield(watcher)
+            message = await receive()
+            if message['type'] == 'http.request' and not message.get('more_body', False):
+                watcher = asyncio.create_task(watch_disconnect())
+            return message
+
+        async def tracked_send(message: Message) -> None:
+            nonlocal response_started
+            if message['type'] == 'http.response.start':
+                response_started = True
+            await send(message)
+
+        app_task = asyncio.create_task(self.app(scope, watched_receive, tracked_send))
+        try:
+            await app_task
+        except asyncio.CancelledError:
+            if not disconnected or not app_task.cancelled():
+                raise
+            REQUESTS_CANCELLED.inc()
+            logger.info(f"Client disconnected, cancelled request: {scope['method']} {scope['path']}")
+            if not response_started:
+                await Result.failed_response(CLIENT_CLOSED_REQUEST, 'Client Closed Request')(scope, receive, send)
+        finally:
+            if watcher is not None and not watcher.done():
+                watcher.cancel()
+            if not app_task.done():
+                app_task.cancel()
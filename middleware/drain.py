@@ -0,0 +1,32 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : drain
+# @Software: PyCharm
+from starlette.types import ASGIApp, Receive, Scope, Send
+
+from domain.result.result import Result
+from utils.health_check import ReadinessState
+from utils.path_match import match_path_prefix
+
+# 排空期间探针仍需响应，否则编排系统会在请求处理完之前强杀进程
+DRAIN_EXEMPT = ('/healthz', '/readyz')
+
+
+class DrainMiddleware:
+    """
+    进程收到退出信号后进入排空状态：新请求直接返回 503 并附带 Connection: close，
+    促使代理改用其他实例；已在处理中的请求不受影响
+    """
+
+    def __init__(self, app: ASGIApp):
+        self.app = app
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send) -> None:
+        if scope['type'] != 'http' or not ReadinessState.draining or match_path_prefix(scope['path'], DRAIN_EXEMPT):
+            await self.app(scope, receive, send)
+            return
+        response = Result.failed_response(503, 'Server is shutting down', headers={'Connection': 'close'})
+        await response(scope, receive, send)
@@ -0,0 +1,53 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : load_shed
+# @Software: PyCharm
+from typing import List
+
+from loguru import logger
+from prometheus_client import Counter
+from starlette.middleware.base import BaseHTTPMiddleware, RequestResponseEndpoint
+from starlette.requests import Request
+from starlette.responses import Response
+from starlette.types import ASGIApp
+
+from domain.result.result import Result
+from utils.path_match import match_path_prefix
+
+LOAD_SHED_REJECTED = Counter(
+    'we0_index_load_shed_rejected_total',
+    'HTTP requests rejected because the concurrency ceiling was reached'
+)
+
+
+class LoadShedMiddleware(BaseHTTPMiddleware):
+    """
+    并发请求数超过 max_in_flight 时直接返回 503 与 Retry-After，避免请求在内存中无限堆积
+    当前并发数通过 we0_index_http_requests_in_flight 指标观察，据此调整上限
+    """
+
+    def __init__(self, app: ASGIApp, max_in_flight: int, retry_after: int, exempt: List[str]):
+        super().__init__(app)
+        self.max_in_flight = max_in_flight
+        self.retry_after = retry_after
+        self.exempt = exempt
+        self.in_flight = 0
+
+    async def dispatch(self, request: Request, call_next: RequestResponseEndpoint) -> Response:
+        if match_path_prefix(request.url.path, self.exempt):
+            return await call_next(request)
+        # 所有请求在同一事件循环内处理，计数无需加锁
+        if self.in_flight >= self.max_in_flight:
+            LOAD_SHED_REJECTED.inc()
+            logger.warning(f"Shedding request, {self.in_flight} in flight: {request.method} {request.url.path}")
+            return Result.failed_response(
+                503, 'Server is overloaded, please retry later', headers={'Retry-After': str(self.retry_after)}
+            )
+        self.in_flight += 1
+        try:
+            return await call_next(request)
+        finally:
+            self.in_flight -= 1
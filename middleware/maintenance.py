@@ -0,0 +1,57 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : maintenance
+# @Software: PyCharm
+from ipaddress import ip_address, ip_network
+
+from starlette.middleware.base import BaseHTTPMiddleware, RequestResponseEndpoint
+from starlette.requests import Request
+from starlette.responses import Response
+
+from domain.enums.maintenance_mode import MaintenanceMode
+from domain.result.result import Result
+from router.versioning import unversioned_path
+from setting.setting import MaintenanceSettings, get_we0_index_settings
+from utils.path_match import match_path_prefix
+
+SAFE_METHODS = ('GET', 'HEAD', 'OPTIONS')
+
+
+class MaintenanceMiddleware(BaseHTTPMiddleware):
+    """
+    维护模式：offline 拒绝所有请求，read-only 仅拒绝写请求，均返回 503 与 Retry-After
+    探针路径与 allow-ips 中的管理地址不受影响；配置随 SIGHUP 热更新，无需重启
+    """
+
+    @staticmethod
+    def is_allowed_ip(request: Request, maintenance: MaintenanceSettings) -> bool:
+        if not request.client or not maintenance.allow_ips:
+            return False
+        try:
+            client = ip_address(request.client.host)
+        except ValueError:
+            return False
+        return any(client in ip_network(network, strict=False) for network in maintenance.allow_ips)
+
+    @staticmethod
+    def is_read(request: Request, maintenance: MaintenanceSettings) -> bool:
+        # 检索等接口同样使用 POST，通过 read-routes 声明为只读
+        return request.method in SAFE_METHODS or unversioned_path(request.url.path) in maintenance.read_routes
+
+    async def dispatch(self, request: Request, call_next: RequestResponseEndpoint) -> Response:
+        maintenance = get_we0_index_settings().maintenance
+        if (
+                maintenance.mode == MaintenanceMode.OFF
+                or match_path_prefix(request.url.path, maintenance.exempt)
+                or (maintenance.mode == MaintenanceMode.READ_ONLY and self.is_read(request, maintenance))
+                or self.is_allowed_ip(request, maintenance)
+        ):
+            return await call_next(request)
+        return Result.failed_response(
+            503,
+            f'Service is under maintenance ({maintenance.mode})',
+            headers={'Retry-After': str(maintenance.retry_after)}
+        )
@@ -0,0 +1,51 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : metrics
+# @Software: PyCharm
+import time
+
+from prometheus_client import Counter, Gauge, Histogram
+from starlette.middleware.base import BaseHTTPMiddleware, RequestResponseEndpoint
+from starlette.requests import Request
+from starlette.responses import Response
+
+REQUEST_COUNT = Counter(
+    'we0_index_http_requests_total',
+    'Total HTTP requests',
+    ['method', 'route', 'status']
+)
+REQUEST_LATENCY = Histogram(
+    'we0_index_http_request_duration_seconds',
+    'HTTP request latency in seconds',
+    ['method', 'route']
+)
+REQUESTS_IN_FLIGHT = Gauge(
+    'we0_index_http_requests_in_flight',
+    'HTTP requests currently being served'
+)
+
+
+def _route_template(request: Request) -> str:
+    # 使用路由模板而非原始路径作为标签，避免标签基数膨胀
+    route = request.scope.get('route')
+    return getattr(route, 'path', 'unmatched')
+
+
+class MetricsMiddleware(BaseHTTPMiddleware):
+
+    async def dispatch(self, request: Request, call_next: RequestResponseEndpoint) -> Response:
+        start = time.perf_counter()
+        status = '5xx'
+        REQUESTS_IN_FLIGHT.inc()
+        try:
+            response = await call_next(request)
+            status = f'{response.status_code // 100}xx'
+            return response
+        finally:
+            REQUESTS_IN_FLIGHT.dec()
+            route = _route_template(request)
+            REQUEST_COUNT.labels(method=request.method, route=route, status=status).inc()
+            REQUEST_LATENCY.labels(method=request.method, route=route).observe(time.perf_counter() - start)
@@ -0,0 +1,128 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : rate_limit
+# @Software: PyCharm
+import math
+import time
+from typing import Dict
+
+from loguru import logger
+from starlette.middleware.base import BaseHTTPMiddleware, RequestResponseEndpoint
+from starlette.requests import Request
+from starlette.responses import Response
+from starlette.types import ASGIApp
+
+from domain.enums.rate_limit_mode import RateLimitMode
+from domain.result.result import Result
+from router.versioning import unversioned_path
+from setting.setting import RateLimitSettings, get_we0_index_settings
+from utils.path_match import match_path_prefix
+
+
+class TokenBucket:
+
+    def __init__(self, capacity: float, refill_rate: float):
+        self.capacity = capacity
+        self.refill_rate = refill_rate  # 每秒补充的令牌数
+        self.tokens = capacity
+        self.updated_at = time.monotonic()
+
+    def consume(self, tokens: float = 1) -> bool:
+        now = time.monotonic()
+        self.tokens = min(self.capacity, self.tokens + (now - self.updated_at) * self.refill_rate)
+        self.updated_at = now
+        if self.tokens >= tokens:
+            self.tokens -= tokens
+            return True
+        return False
+
+    def retry_after(self, tokens: float = 1) -> int:
+        return max(1, math.ceil((tokens - self.tokens) / self.refill_rate))
+
+    def is_idle(self, now: float) -> bool:
+        # 空闲时间足以补满令牌的桶与新建的桶等价，可以安全淘汰
+        return now - self.updated_at >= self.capacity / self.refill_rate
+
+
+class RateLimitMiddleware(BaseHTTPMiddleware):
+    """
+    按客户端维度的令牌桶限流，单个客户端超限不会影响其他客户端
+    携带 API Key 的请求按 key 所属 tier 的限额计数（ApiKeyMiddleware 需位于外层），其余请求按 IP 使用默认限额
+    每次请求按路由的 cost 扣减令牌，开销大的接口一次消耗多个令牌
+    限流参数每次请求从当前配置快照读取，支持 SIGHUP 热更新
+    运行在单个事件循环中，桶的读写之间没有 await，无需加锁；定期淘汰空闲的桶以限制内存占用
+    """
+    EVICT_INTERVAL = 60
+
+    def __init__(self, app: ASGIApp):
+        super().__init__(app)
+        self.buckets: Dict[str, TokenBucket] = {}
+        self.rate_limit: RateLimitSettings | None = None
+        self.evicted_at = time.monotonic()
+
+    def evict_idle(self) -> None:
+        now = time.monotonic()
+        if now - self.evicted_at < self.EVICT_INTERVAL:
+            return
+        self.evicted_at = now
+        for key in [key for key, bucket in self.buckets.items() if bucket.is_idle(now)]:
+            del self.buckets[key]
+
+    def get_settings(self) -> RateLimitSettings:
+        rate_limit = get_we0_index_settings().rate_limit
+        if rate_limit is not self.rate_limit:
+            # 配置变更后按新参数重新计数
+            self.buckets.clear()
+            self.rate_limit = rate_limit
+        return rate_limit
+
+    @staticmethod
+    def get_client_key(request: Request, key_name: str | None) -> str:
+        # 已认证的请求按 API Key 计数，同一个 key 从多个地址访问共享限额
+        if key_name:
+            return f'key:{key_name}'
+        return f"ip:{request.client.host if request.client else 'anonymous'}"
+
+    async def dispatch(self, request: Request, call_next: RequestResponseEndpoint) -> Response:
+        rate_limit = self.get_settings()
+        key_name = getattr(request.state, 'api_key', None)
+        if (
+                not rate_limit.enabled
+                or request.method == 'OPTIONS'
+                or key_name in rate_limit.exempt_keys
+                or match_path_prefix(request.url.path, rate_limit.exempt)
+        ):
+            return await call_next(request)
+        self.evict_idle()
+        key = self.get_client_key(request, key_name)
+        bucket = self.buckets.get(key)
+        if bucket is None:
+            per_minute, burst = rate_limit.get_limits(key_name)
+            bucket = self.buckets[key] = TokenBucket(burst, per_minute / 60)
+        path = unversioned_path(request.url.path)
+        cost = rate_limit.get_cost(path)
+        exceeded = not bucket.consume(cost)
+        if exceeded and rate_limit.get_mode(path) == RateLimitMode.SHADOW:
+            logger.warning(f"Rate limit exceeded (shadow mode) for {key}: {request.method} {request.url.path}")
+            response = await call_next(request)
+            response.headers['X-RateLimit-Exceeded'] = 'true'
+            response.headers['X-RateLimit-Remaining'] = str(int(bucket.tokens))
+            response.headers['X-RateLimit-Cost'] = str(cost)
+            return response
+        if exceeded:
+            return Result.failed_response(
+                429,
+                'Too Many Requests',
+                headers={
+                    'Retry-After': str(bucket.retry_after(cost)),
+                    'X-RateLimit-Remaining': str(int(bucket.tokens)),
+                    'X-RateLimit-Cost': str(cost),
+                }
+            )
+        response = await call_next(request)
+        response.headers['X-RateLimit-Remaining'] = str(int(bucket.tokens))
+        response.headers['X-RateLimit-Cost'] = str(cost)
+        return response
@@ -0,0 +1,63 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : request_id
+# @Software: PyCharm
+import random
+import time
+import uuid
+
+from loguru import logger
+from starlette.middleware.base import BaseHTTPMiddleware, RequestResponseEndpoint
+from starlette.requests import Request
+from starlette.responses import Response
+
+from constants.constants import Constants
+from setting.setting import get_we0_index_settings
+from utils.request_context import request_id_context
+
+
+class RequestIdMiddleware(BaseHTTPMiddleware):
+    """
+    为每个请求生成（或沿用上游传入的）request id，
+    写入上下文与响应头，并在请求结束时按采样配置输出一条访问日志
+    """
+
+    @staticmethod
+    def should_log(status_code: int, latency: float) -> bool:
+        log = get_we0_index_settings().log
+        if status_code >= 400 or latency >= log.slow_request_threshold:
+            return True
+        return random.random() < log.access_sample_rate
+
+    def log_access(self, request: Request, status_code: int, start: float) -> None:
+        latency = (time.perf_counter() - start) * 1000
+        if self.should_log(status_code, latency / 1000):
+            logger.bind(
+                method=request.method,
+                path=request.url.path,
+                status=status_code,
+                latency=round(latency, 2),
+            ).info(f"{request.method} {request.url.path} {status_code} {latency:.2f}ms")
+
+    async def dispatch(self, request: Request, call_next: RequestResponseEndpoint) -> Response:
+        request_id = request.headers.get(Constants.Header.REQUEST_ID) or uuid.uuid4().hex
+        token = request_id_context.set(request_id)
+        # 外层异常处理器运行时上下文已重置，通过 request.state 传递
+        request.state.request_id = request_id
+        start = time.perf_counter()
+        try:
+            with logger.contextualize(request_id=request_id):
+                try:
+                    response = await call_next(request)
+                except Exception:
+                    # 中间件自身抛出的异常由外层 ServerErrorMiddleware 转换为 500，这里先补记访问日志
+                    self.log_access(request, 500, start)
+                    raise
+                self.log_access(request, response.status_code, start)
+            response.headers[Constants.Header.REQUEST_ID] = request_id
+            return response
+        finally:
+            request_id_context.reset(token)
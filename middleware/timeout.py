@@ -0,0 +1,65 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : timeout
+# @Software: PyCharm
+import asyncio
+from typing import Dict
+
+from loguru import logger
+from starlette.types import ASGIApp, Message, Receive, Scope, Send
+
+from domain.result.result import Result
+from router.versioning import unversioned_path
+
+
+class TimeoutMiddleware:
+    """
+    为请求设置处理时限，超时后取消下游处理（数据库、向量库调用随之中断，经 SingleFlight 合并的调用除外，见 SingleFlight）并返回 503；
+    响应已开始发送时无法再改写状态码，只中断处理并记录日志
+    routes 以路径前缀覆盖默认时限，最长前缀优先；时限 <= 0 表示不限制
+    使用纯 ASGI 实现：BaseHTTPMiddleware 的 call_next 在外层任务组中运行下游应用，超时后处理仍会继续
+    """
+
+    def __init__(self, app: ASGIApp, default: float, routes: Dict[str, float] | None = None):
+        self.app = app
+        self.default = default
+        self.routes = sorted((routes or {}).items(), key=lambda item: len(item[0]), reverse=True)
+
+    def get_timeout(self, path: str) -> float:
+        for prefix, timeout in self.routes:
+            if path.startswith(prefix):
+                return timeout
+        return self.default
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send) -> None:
+        if scope['type'] != 'http':
+            await self.app(scope, receive, send)
+            return
+        timeout = self.get_timeout(unversioned_path(scope['path']))
+        if timeout <= 0:
+            await self.app(scope, receive, send)
+            return
+
+        response_started = False
+
+        async def tracked_send(message: Message) -> None:
+            nonlocal response_started
+            if message['type'] == 'http.response.start':
+                response_started = True
+            await send(message)
+
+        deadline = asyncio.timeout(timeout)
+        try:
+            # 到期时取消当前任务中的下游处理，CancelledError 在退出时转换为 TimeoutError
+            async with deadline:
+                await self.app(scope, receive, tracked_send)
+        except TimeoutError:
+            if not deadline.expired():
+                # 下游自身抛出的 TimeoutError，不是请求超时
+                raise
+            logger.warning(f"Request timed out after {timeout}s: {scope['method']} {scope['path']}")
+            if not response_started:
+                await Result.failed_response(503, f"Request timed out after {timeout}s")(scope, receive, send)
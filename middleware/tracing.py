@@ -0,0 +1,54 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : tracing
+# @Software: PyCharm
+from opentelemetry import propagate, trace
+from opentelemetry.exporter.otlp.proto.http.trace_exporter import OTLPSpanExporter
+from opentelemetry.instrumentation.sqlalchemy import SQLAlchemyInstrumentor
+from opentelemetry.sdk.resources import SERVICE_NAME, Resource
+from opentelemetry.sdk.trace import TracerProvider
+from opentelemetry.sdk.trace.export import BatchSpanProcessor
+from opentelemetry.trace import SpanKind, Status, StatusCode
+from starlette.middleware.base import BaseHTTPMiddleware, RequestResponseEndpoint
+from starlette.requests import Request
+from starlette.responses import Response
+
+from setting.setting import TracingSettings
+from utils.request_context import get_request_id
+
+tracer = trace.get_tracer('we0-index')
+
+
+def setup_tracing(tracing: TracingSettings) -> None:
+    """配置 OTLP 导出，并对之后创建的 sqlalchemy engine 自动生成子 span"""
+    provider = TracerProvider(resource=Resource.create({SERVICE_NAME: tracing.service_name}))
+    provider.add_span_processor(BatchSpanProcessor(OTLPSpanExporter(endpoint=tracing.endpoint)))
+    trace.set_tracer_provider(provider)
+    # 语句使用绑定参数，span 中只记录 SQL 模板而不含参数值
+    SQLAlchemyInstrumentor().instrument(tracer_provider=provider)
+
+
+class TracingMiddleware(BaseHTTPMiddleware):
+
+    async def dispatch(self, request: Request, call_next: RequestResponseEndpoint) -> Response:
+        context = propagate.extract(request.headers)
+        with tracer.start_as_current_span(
+                f'{request.method} {request.url.path}', context=context, kind=SpanKind.SERVER
+        ) as span:
+            span.set_attribute('http.request.method', request.method)
+            span.set_attribute('url.path', request.url.path)
+            request_id = get_request_id()
+            if request_id:
+                span.set_attribute('request_id', request_id)
+            response = await call_next(request)
+            route = getattr(request.scope.get('route'), 'path', None)
+            if route:
+                span.update_name(f'{request.method} {route}')
+                span.set_attribute('http.route', route)
+            span.set_attribute('http.response.status_code', response.status_code)
+            if response.status_code >= 500:
+                span.set_status(Status(StatusCode.ERROR))
+            return response
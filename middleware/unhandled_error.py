@@ -0,0 +1,46 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : unhandled_error
+# @Software: PyCharm
+from starlette.exceptions import HTTPException
+from starlette.requests import Request
+from starlette.types import ASGIApp, Message, Receive, Scope, Send
+
+from exception.handler import unhandled_exception_response
+
+
+class UnhandledErrorMiddleware:
+    """
+    在最内层将路由抛出的未处理异常转换为 500 响应：Exception 的处理器由最外层的 ServerErrorMiddleware 调用，
+    异常经过时所有中间件都拿不到响应，访问日志、指标、X-Request-ID 与 CORS 响应头都会缺失
+    HTTPException 仍向外抛出，由请求体大小限制等中间件自行处理
+    """
+
+    def __init__(self, app: ASGIApp):
+        self.app = app
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send) -> None:
+        if scope['type'] != 'http':
+            await self.app(scope, receive, send)
+            return
+
+        response_started = False
+
+        async def tracked_send(message: Message) -> None:
+            nonlocal response_started
+            if message['type'] == 'http.response.start':
+                response_started = True
+            await send(message)
+
+        try:
+            await self.app(scope, receive, tracked_send)
+        except HTTPException:
+            raise
+        except Exception as exc:
+            if response_started:
+                raise
+            response = await unhandled_exception_response(Request(scope, receive), exc)
+            await response(scope, receive, send)
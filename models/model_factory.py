@@ -12,7 +12,9 @@ from openai.types import CreateEmbeddingResponse
 from openai.types.chat import ChatCompletionMessageParam, ChatCompletion
 
 from clients import jina
+from clients.http import create_http_client
 from domain.enums.model_provider import ModelType
+from setting.setting import get_we0_index_settings
 
 
 class ModelInstance:
@@ -20,22 +22,37 @@ class ModelInstance:
     def __init__(self, model_type: ModelType, model_name: str):
         self.model_type = model_type
         self.model_name = model_name
+        # 客户端按实例复用，共享连接池
+        self._client = None
+
+    def get_client(self):
+        if self._client is None:
+            match self.model_type:
+                case ModelType.OPENAI:
+                    import openai
+                    # 重试由 RetryingAsyncClient 负责，关闭 SDK 自带的重试避免叠加
+                    self._client = openai.AsyncClient(
+                        max_retries=0,
+                        timeout=get_we0_index_settings().http_client.timeout,
+                        http_client=create_http_client()
+                    )
+                case ModelType.JINA:
+                    self._client = jina.AsyncClient()
+                case _:
+                    raise Exception(f"Unknown model type: {self.model_type}")
+        return self._client
 
     def get_completions_client(self):
         match self.model_type:
             case ModelType.OPENAI:
-                import openai
-                return openai.AsyncClient().chat.completions
+                return self.get_client().chat.completions
             case _:
                 raise Exception(f"Unknown model type: {self.model_type}")
 
     def get_embedding_client(self):
         match self.model_type:
-            case ModelType.OPENAI:
-                import openai
-                return openai.AsyncClient().embeddings
-            case ModelType.JINA:
-                return jina.AsyncClient().embeddings
+            case ModelType.OPENAI | ModelType.JINA:
+                return self.get_client().embeddings
             case _:
                 raise Exception(f"Unknown model type: {self.model_type}")
 
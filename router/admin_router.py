@@ -0,0 +1,56 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : admin_router
+# @Software: PyCharm
+from datetime import datetime, timezone
+from typing import List
+
+from fastapi import APIRouter
+from loguru import logger
+
+from config.loguru import Log
+from domain.request.log_level_request import LogLevelRequest
+from domain.response.api_key_response import ApiKeyResponse
+from domain.response.log_level_response import LogLevelResponse
+from domain.result.result import Result
+from middleware.api_key import api_key_last_used
+from setting.setting import get_we0_index_settings
+
+admin_router = APIRouter()
+
+
+@admin_router.get('/loglevel', response_model=Result[LogLevelResponse])
+async def get_log_level():
+    """
+    查询当前日志级别
+    """
+    return Result.ok(data=LogLevelResponse(level=Log.get_level()))
+
+
+@admin_router.put('/loglevel', response_model=Result[LogLevelResponse])
+async def set_log_level(log_level_request: LogLevelRequest):
+    """
+    运行期调整日志级别，立即生效；重启或 SIGHUP 重新加载配置后恢复为配置文件中的级别
+    """
+    previous = Log.get_level()
+    Log.set_level(log_level_request.level)
+    logger.warning(f"Log level changed: {previous} -> {log_level_request.level}")
+    return Result.ok(data=LogLevelResponse(level=log_level_request.level))
+
+
+@admin_router.get('/api-keys', response_model=Result[List[ApiKeyResponse]])
+async def list_api_keys():
+    """
+    列出已配置的 API Key 及其最近使用时间，用于审计与清理长期未使用的 key；不返回摘要
+    """
+    return Result.ok(data=[
+        ApiKeyResponse(
+            name=key.name,
+            scopes=key.scopes,
+            last_used_at=datetime.fromtimestamp(api_key_last_used[key.name], tz=timezone.utc)
+            if key.name in api_key_last_used else None,
+        ) for key in get_we0_index_settings().auth.api_keys
+    ])
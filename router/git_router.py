@@ -16,6 +16,7 @@ from domain.entity.task_context import TaskContext
 from domain.request.git_index_request import GitIndexRequest
 from domain.response.add_index_response import AddIndexResponse, FileInfoResponse
 from domain.result.result import Result
+from exception.exception import UpstreamException, ValidationException
 from extensions.ext_manager import ExtManager
 from setting.setting import get_we0_index_settings
 from utils.git_parse import parse_git_url
@@ -100,64 +101,62 @@ async def clone_and_index(git_index_request: GitIndexRequest) -> Result[AddIndex
     - SSH 密钥认证: 使用 git@github.com:user/repo.git 格式的 URL，可选择提供 ssh_key_path
     - HTTPS + 用户名密码: 提供 username 和 password 参数
     """
-    try:
-        if not git_index_request.uid:
-            git_index_request.uid = 'default_uid'
-
-        domain, owner, repo = parse_git_url(git_index_request.repo_url)
-        repo_abs_path = f'{domain}/{owner}/{repo}'
-        repo_id = Helper.generate_fixed_uuid(f"{git_index_request.uid}{repo_abs_path}:")
-
-        # 准备认证后的仓库 URL
-        auth_repo_url = _prepare_repo_url_with_auth(
-            git_index_request.repo_url,
-            git_index_request.username,
-            git_index_request.password,
-            git_index_request.access_token
-        )
+    if not git_index_request.uid:
+        git_index_request.uid = 'default_uid'
+
+    domain, owner, repo = parse_git_url(git_index_request.repo_url)
+    if domain is None:
+        raise ValidationException(f"Unsupported git url: {git_index_request.repo_url}")
+    repo_abs_path = f'{domain}/{owner}/{repo}'
+    repo_id = Helper.generate_fixed_uuid(f"{git_index_request.uid}{repo_abs_path}:")
+
+    # 准备认证后的仓库 URL
+    auth_repo_url = _prepare_repo_url_with_auth(
+        git_index_request.repo_url,
+        git_index_request.username,
+        git_index_request.password,
+        git_index_request.access_token
+    )
 
-        file_count = 0  # 初始化 file_count 变量
-
-        async with aiofiles.tempfile.TemporaryDirectory() as tmp_dir:
-            try:
-                # Clone repository with proper error handling
-                await asyncio.to_thread(
-                    Repo.clone_from,
-                    auth_repo_url,
-                    tmp_dir
-                )
-            except Exception as e:
-                error_msg = f"Failed to clone repository {git_index_request.repo_url}: {type(e).__name__}: {e}"
-                logger.error(error_msg)
-                return Result.failed(message=error_msg)
-            # 遍历并处理文件
-            tasks = []
-            for root, dirs, files in os.walk(tmp_dir):
-                # 忽略 .git 等隐藏目录
-                dirs[:] = [d for d in dirs if not d.startswith('.')]
-                for file in files:
-                    if not file.startswith('.'):
-                        relative_path = os.path.relpath(os.path.join(root, file), start=tmp_dir)
-                        tasks.append(asyncio.create_task(_process_file(
-                            uid=git_index_request.uid,
-                            repo_id=repo_id,
-                            repo_path=repo_abs_path,
-                            base_dir=tmp_dir,
-                            relative_path=relative_path
-                        )))
-            for task in asyncio.as_completed(tasks):
-                await task
-                file_count += 1
-
-            logger.info(f"Successfully processed {file_count} files from repository {repo_abs_path}")
-
-        return Result.ok(data=AddIndexResponse(
-            repo_id=repo_id,
-            file_infos=[]
-        ))
-    except Exception as e:
-        logger.exception(e)
-        return Result.failed(message=f"{type(e).__name__}: {e}")
+    file_count = 0  # 初始化 file_count 变量
+
+    async with aiofiles.tempfile.TemporaryDirectory() as tmp_dir:
+        try:
+            # Clone repository with proper error handling
+            await asyncio.to_thread(
+                Repo.clone_from,
+                auth_repo_url,
+                tmp_dir
+            )
+        except Exception as e:
+            logger.error(f"Failed to clone repository {git_index_request.repo_url}: {type(e).__name__}: {e}")
+            # 异常信息可能包含带认证信息的 URL，不返回给调用方
+            raise UpstreamException(f"Failed to clone repository {git_index_request.repo_url}") from e
+        # 遍历并处理文件
+        tasks = []
+        for root, dirs, files in os.walk(tmp_dir):
+            # 忽略 .git 等隐藏目录
+            dirs[:] = [d for d in dirs if not d.startswith('.')]
+            for file in files:
+                if not file.startswith('.'):
+                    relative_path = os.path.relpath(os.path.join(root, file), start=tmp_dir)
+                    tasks.append(asyncio.create_task(_process_file(
+                        uid=git_index_request.uid,
+                        repo_id=repo_id,
+                        repo_path=repo_abs_path,
+                        base_dir=tmp_dir,
+                        relative_path=relative_path
+                    )))
+        for task in asyncio.as_completed(tasks):
+            await task
+            file_count += 1
+
+        logger.info(f"Successfully processed {file_count} files from repository {repo_abs_path}")
+
+    return Result.ok(data=AddIndexResponse(
+        repo_id=repo_id,
+        file_infos=[]
+    ))
 
 
 git_router.add_api_route('/clone_and_index', clone_and_index, methods=['POST'], response_model=Result[AddIndexResponse])
@@ -23,17 +23,38 @@ from domain.request.retrieval_request import RetrievalRequest
 from domain.response.add_index_by_file_response import AddIndexByFileResponse
 from domain.response.add_index_response import AddIndexResponse, FileInfoResponse
 from domain.result.result import Result
+from exception.exception import ValidationException
 from extensions.ext_manager import ExtManager
 from models.model_factory import ModelInstance
 from setting.setting import get_we0_index_settings
 from utils.helper import Helper
 from utils.mimetype_util import guess_mimetype_and_extension
+from utils.single_flight import SingleFlight
 from utils.vector_helper import VectorHelper
 
 vector_router = APIRouter()
 
 settings = get_we0_index_settings()
 
+# 同一文件、同一内容的并发写入只执行一次，重复请求等待并复用首个请求的结果；写入完成即释放 key
+# 写入不随单个请求的超时或断开而取消，见 SingleFlight
+_write_flight = SingleFlight()
+
+
+async def _build_and_upsert(task_context: TaskContext) -> List[Document]:
+    documents: List[Document] = await VectorHelper.build_and_embedding_segment(task_context)
+    if documents:
+        await ExtManager.vector.upsert(documents)
+    return documents
+
+
+async def _coalesced_upsert(task_context: TaskContext) -> List[Document]:
+    content_hash = Helper.generate_text_hash(task_context.blob.as_bytes())
+    return await _write_flight.do(
+        (task_context.repo_id, task_context.file_id, content_hash),
+        lambda: _build_and_upsert(task_context)
+    )
+
 
 async def _upsert_index(uid: str, repo_abs_path: str, repo_id: str, file_info: AddFileInfo) -> FileInfoResponse:
     mimetype, extension = guess_mimetype_and_extension(file_info.relative_path)
@@ -51,12 +72,7 @@ async def _upsert_index(uid: str, repo_abs_path: str, repo_id: str, file_info: A
             extension=extension
         )
     )
-    try:
-        documents: List[Document] = await VectorHelper.build_and_embedding_segment(task_context)
-        if documents:
-            await ExtManager.vector.upsert(documents)
-    except Exception as e:
-        raise e
+    await _coalesced_upsert(task_context)
     return FileInfoResponse(
         file_id=file_id,
         relative_path=file_info.relative_path
@@ -98,26 +114,22 @@ async def upsert_index_by_file(
     mimetype, extension = guess_mimetype_and_extension(relative_path)
 
     file_id = Helper.generate_fixed_uuid(f"{uid}:{repo_abs_path}:{relative_path}")
+    data = await file.read()
 
     task_context = TaskContext(
         repo_id=repo_id,
         file_id=file_id,
         relative_path=relative_path,
         blob=Blob.from_data(
-            data=await file.read(),
+            data=data,
             mimetype=mimetype,
             extension=extension
         )
     )
-    try:
-        documents: List[Document] = await VectorHelper.build_and_embedding_segment(task_context)
-        if documents:
-            await ExtManager.vector.upsert(documents)
-            return Result.ok(data=AddIndexByFileResponse(repo_id=repo_id, file_id=file_id))
-        else:
-            return Result.failed(message=f"Not Content")
-    except Exception as e:
-        return Result.failed(message=f"{type(e).__name__}: {e}")
+    documents = await _coalesced_upsert(task_context)
+    if not documents:
+        raise ValidationException("Not Content")
+    return Result.ok(data=AddIndexByFileResponse(repo_id=repo_id, file_id=file_id))
 
 
 @vector_router.post('/drop_index', response_model=Result)
@@ -125,11 +137,8 @@ async def drop_index(drop_index_request: DropIndexRequest):
     """
     删除索引的全部向量
     """
-    try:
-        await ExtManager.vector.drop(repo_id=drop_index_request.repo_id)
-        return Result.ok()
-    except Exception as e:
-        return Result.failed(message=f"{type(e).__name__}: {e}")
+    await ExtManager.vector.drop(repo_id=drop_index_request.repo_id)
+    return Result.ok()
 
 
 @vector_router.post('/delete_index', response_model=Result)
@@ -137,20 +146,14 @@ async def delete_index(delete_index_request: DeleteIndexRequest):
     """
     删除索引的指定向量
     """
-    try:
-        await ExtManager.vector.delete(repo_id=delete_index_request.repo_id, file_ids=delete_index_request.file_ids)
-        return Result.ok()
-    except Exception as e:
-        return Result.failed(message=f"{type(e).__name__}: {e}")
+    await ExtManager.vector.delete(repo_id=delete_index_request.repo_id, file_ids=delete_index_request.file_ids)
+    return Result.ok()
 
 
 @vector_router.post('/all_index', response_model=Result)
 async def all_index(all_index_request: AllIndexRequest):
-    try:
-        all_meta = await ExtManager.vector.all_meta(repo_id=all_index_request.repo_id)
-        return Result.ok(data=all_meta)
-    except Exception as e:
-        return Result.failed(message=f"{type(e).__name__}: {e}")
+    all_meta = await ExtManager.vector.all_meta(repo_id=all_index_request.repo_id)
+    return Result.ok(data=all_meta)
 
 
 async def retrieval(
@@ -163,16 +166,13 @@ async def retrieval(
     }
     相似度匹配，从整个仓库或指定仓库的部分文件
     """
-    try:
-        embedding_model: ModelInstance = await ExtManager.vector.get_embedding_model()
-        vector_data = await embedding_model.create_embedding([retrieval_request.query])
-        documents = await ExtManager.vector.search_by_vector(
-            repo_id=retrieval_request.repo_id, file_ids=retrieval_request.file_ids, query_vector=vector_data[0]
-        )
-        retrieval_segment_list = [document.meta for document in documents]
-        return Result.ok(data=retrieval_segment_list)
-    except Exception as e:
-        return Result.failed(message=f"{type(e).__name__}: {e}")
+    embedding_model: ModelInstance = await ExtManager.vector.get_embedding_model()
+    vector_data = await embedding_model.create_embedding([retrieval_request.query])
+    documents = await ExtManager.vector.search_by_vector(
+        repo_id=retrieval_request.repo_id, file_ids=retrieval_request.file_ids, query_vector=vector_data[0]
+    )
+    retrieval_segment_list = [document.meta for document in documents]
+    return Result.ok(data=retrieval_segment_list)
 
 
 vector_router.add_api_route('/retrieval', retrieval, methods=['POST'], response_model=Result[List[DocumentMeta]])
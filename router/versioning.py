@@ -0,0 +1,54 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : versioning
+# @Software: PyCharm
+import re
+from typing import Awaitable, Callable
+
+from fastapi import APIRouter, Request, Response
+from loguru import logger
+from prometheus_client import Counter
+
+API_VERSIONS = ('v1',)
+VERSION_PREFIX_PATTERN = re.compile(r'^/v\d+(?=/)')
+
+DEPRECATED_REQUESTS = Counter(
+    'we0_index_deprecated_requests_total',
+    'Requests served by deprecated routes',
+    ['route', 'api_key']
+)
+
+
+def unversioned_path(path: str) -> str:
+    """去掉 /v1 等版本前缀，按路径前缀配置的超时、请求体限制、scopes 等对所有版本统一生效"""
+    return VERSION_PREFIX_PATTERN.sub('', path, count=1)
+
+
+def deprecated(successor: str, sunset: str | None = None) -> Callable[[Request, Response], Awaitable[None]]:
+    """
+    标记路由已弃用的依赖：响应附带 Deprecation、Sunset 与指向新版本的 Link 头，并记录调用方便于追踪迁移进度
+    successor 为新版本的路径前缀，sunset 为计划下线时间（HTTP-date）
+    """
+
+    async def mark_deprecated(request: Request, response: Response) -> None:
+        response.headers['Deprecation'] = 'true'
+        if sunset:
+            response.headers['Sunset'] = sunset
+        response.headers['Link'] = f'<{successor}{request.url.path}>; rel="successor-version"'
+        api_key = getattr(request.state, 'api_key', None) or '-'
+        route = getattr(request.scope.get('route'), 'path', request.url.path)
+        DEPRECATED_REQUESTS.labels(route=route, api_key=api_key).inc()
+        client = request.client.host if request.client else '-'
+        logger.info(f"Deprecated route called: {request.method} {request.url.path}, client: {client}, api key: {api_key}")
+
+    return mark_deprecated
+
+
+def create_version_router(version: str) -> APIRouter:
+    """
+    新增版本时先注册需要覆盖的路由，再 include 共享的路由（先注册的路由优先匹配）
+    """
+    return APIRouter(prefix=f'/{version}')
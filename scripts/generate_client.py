@@ -0,0 +1,270 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+"""
+从 FastAPI 路由注册表（与 OpenAPI 文档同源）生成带类型的异步客户端：
+
+    python scripts/generate_client.py          # 重新生成
+    python scripts/generate_client.py --check  # 生成结果与已提交的文件不一致时返回非零退出码
+
+输出 clients/we0_index/，请求与响应模型按 domain 下的 pydantic 模型生成独立的副本，客户端不依赖服务端代码；
+生成的文件随代码一同提交，路由或模型变更后重新执行即可，请勿手动修改
+"""
+import os
+import sys
+import types
+import typing
+from collections import defaultdict
+from typing import Dict, List, Set
+
+# Add project root to path
+ROOT_PATH = os.path.dirname(os.path.dirname(os.path.abspath(__file__)))
+sys.path.insert(0, ROOT_PATH)
+
+from fastapi import params  # noqa: E402
+from fastapi.routing import APIRoute  # noqa: E402
+from pydantic import BaseModel  # noqa: E402
+
+from domain.result.result import Result  # noqa: E402
+from launch.launch import app  # noqa: E402
+
+VERSION = 'v1'
+OUTPUT_DIR = os.path.join(ROOT_PATH, 'clients', 'we0_index')
+OUTPUT_PATH = os.path.join(OUTPUT_DIR, 'client.py')
+INIT_PATH = os.path.join(OUTPUT_DIR, '__init__.py')
+
+HEADER = '''# Code generated by scripts/generate_client.py. DO NOT EDIT.
+# -*- coding: utf-8 -*-
+'''
+
+RESULT = '''
+
+T = TypeVar('T')
+
+
+class Result(BaseModel, Generic[T]):
+    code: int
+    message: str
+    data: T | None = None
+    success: bool
+    request_id: str | None = None
+'''
+
+RUNTIME = '''
+
+class We0IndexApiError(Exception):
+    """服务端返回 4xx / 5xx；result 为服务端的 Result 错误响应（非 JSON 响应时为 None）"""
+
+    def __init__(self, status_code: int, result: Result | None, text: str):
+        self.status_code = status_code
+        self.result = result
+        super().__init__(f"HTTP {status_code}: {result.message if result else text}")
+
+
+def _parse(response: httpx.Response, model):
+    is_json = response.headers.get('content-type', '').startswith('application/json')
+    if response.is_error:
+        raise We0IndexApiError(
+            response.status_code, Result.model_validate(response.json()) if is_json else None, response.text
+        )
+    return model.model_validate(response.json())
+'''
+
+CLIENT = '''
+
+class We0IndexClient:
+
+    def __init__(self, base_url: str, api_key: str | None = None, timeout: float = 300, **kwargs):
+        headers = dict(kwargs.pop('headers', None) or {{}})
+        if api_key:
+            headers['X-API-Key'] = api_key
+        self._client = httpx.AsyncClient(base_url=base_url, timeout=timeout, headers=headers, **kwargs)
+{groups}
+
+    async def aclose(self) -> None:
+        await self._client.aclose()
+
+    async def __aenter__(self) -> 'We0IndexClient':
+        return self
+
+    async def __aexit__(self, *args) -> None:
+        await self.aclose()
+'''
+
+
+class TypeRenderer:
+    """
+    将类型注解渲染为源码表达式，并收集需要导入的名称；
+    服务端的 pydantic 模型记录到 models 中，按依赖顺序生成为客户端内的独立模型，其余类型只允许来自标准库
+    """
+
+    def __init__(self):
+        self.imports: Dict[str, Set[str]] = defaultdict(set)
+        self.models: Dict[type, List[str]] = {}
+
+    def render(self, tp) -> str:
+        if tp is None or tp is type(None):
+            return 'None'
+        if tp is typing.Any:
+            self.imports['typing'].add('Any')
+            return 'Any'
+        if tp is Result:
+            return 'Result'
+        metadata = getattr(tp, '__pydantic_generic_metadata__', None)
+        if metadata and metadata['origin'] is not None:
+            args = ', '.join(self.render(arg) for arg in metadata['args'])
+            return f"{self.render(metadata['origin'])}[{args}]"
+        if isinstance(tp, type) and issubclass(tp, BaseModel):
+            if tp not in self.models:
+                self.models[tp] = []  # 先占位，避免循环引用时重复生成
+                lines = self.render_model(tp)
+                # 重新插入到末尾，字段引用的模型总是排在引用方之前
+                del self.models[tp]
+                self.models[tp] = lines
+            return tp.__name__
+        origin = typing.get_origin(tp)
+        if origin in (typing.Union, types.UnionType):
+            return ' | '.join(self.render(arg) for arg in typing.get_args(tp))
+        if origin is not None:
+            name = {list: 'List', dict: 'Dict', tuple: 'Tuple'}[origin]
+            self.imports['typing'].add(name)
+            return f"{name}[{', '.join(self.render(arg) for arg in typing.get_args(tp))}]"
+        if tp.__module__ == 'builtins':
+            return tp.__name__
+        if tp.__module__.split('.')[0] not in sys.stdlib_module_names:
+            raise TypeError(f"{tp.__module__}.{tp.__name__} can not be used in the generated client")
+        self.imports[tp.__module__].add(tp.__name__)
+        return tp.__name__
+
+    def render_model(self, model: type[BaseModel]) -> List[str]:
+        lines = [f"class {model.__name__}(BaseModel):"]
+        for name, field in model.model_fields.items():
+            options = []
+            if field.default_factory is not None:
+                if getattr(field.default_factory, '__module__', None) != 'builtins':
+                    raise TypeError(f"{model.__name__}.{name}: only builtin default factories are supported")
+                options.append(f"default_factory={field.default_factory.__name__}")
+            elif not field.is_required():
+                options.append(f"default={field.default!r}")
+            if field.alias and field.alias != name:
+                options.append(f"alias={field.alias!r}")
+            if field.description:
+                options.append(f"description={field.description!r}")
+            annotation = self.render(field.annotation)
+            if not options:
+                lines.append(f"    {name}: {annotation}")
+            elif len(options) == 1 and options[0].startswith('default='):
+                lines.append(f"    {name}: {annotation} = {field.default!r}")
+            else:
+                self.imports['pydantic'].add('Field')
+                lines.append(f"    {name}: {annotation} = Field({', '.join(options)})")
+        return lines
+
+
+def render_method(route: APIRoute, renderer: TypeRenderer) -> List[str]:
+    arguments, request_options = [], []
+    json_params = [param for param in route.dependant.body_params if not isinstance(param.field_info, params.Form)]
+    form_params = [param for param in route.dependant.body_params if isinstance(param.field_info, params.Form)]
+    for param in json_params:
+        arguments.append(f"{param.name}: {renderer.render(param.type_)}")
+        request_options.append(f"json={param.name}.model_dump(mode='json', by_alias=True)")
+    if form_params:
+        data, files = [], []
+        for param in form_params:
+            if isinstance(param.field_info, params.File):
+                arguments.append(f"{param.name}: bytes")
+                files.append(f"'{param.alias}': {param.name}")
+            else:
+                arguments.append(f"{param.name}: {renderer.render(param.type_)}")
+                data.append(f"'{param.alias}': {param.name}")
+        request_options.append(f"data={{{', '.join(data)}}}")
+        if files:
+            request_options.append(f"files={{{', '.join(files)}}}")
+    if route.dependant.query_params:
+        for param in route.dependant.query_params:
+            arguments.append(f"{param.name}: {renderer.render(param.type_)}")
+        query = ', '.join(f"'{param.alias}': {param.name}" for param in route.dependant.query_params)
+        request_options.append(f"params={{{query}}}")
+
+    response_type = renderer.render(route.response_model) if route.response_model else 'Result'
+    http_method = sorted(route.methods)[0].lower()
+    signature = ', '.join(['self', *arguments])
+    lines = [f"    async def {route.name}({signature}) -> {response_type}:"]
+    summary = (route.description or '').strip().splitlines()
+    if summary:
+        lines.append(f'        """{summary[0].strip()}"""')
+    call_arguments = ', '.join([repr(route.path), *request_options])
+    lines.append(f"        response = await self._client.{http_method}({call_arguments})")
+    lines.append(f"        return _parse(response, {response_type})")
+    return lines
+
+
+def generate() -> Dict[str, str]:
+    """返回 文件路径 -> 内容，包含 client.py 与导出全部名称的 __init__.py"""
+    renderer = TypeRenderer()
+    renderer.imports['typing'].update({'Generic', 'TypeVar'})
+    renderer.imports['pydantic'].add('BaseModel')
+    groups: Dict[str, List[List[str]]] = defaultdict(list)
+    for route in app.routes:
+        if not isinstance(route, APIRoute) or not route.path.startswith(f'/{VERSION}/'):
+            continue
+        group = route.path.split('/')[2]
+        groups[group].append(render_method(route, renderer))
+
+    classes = []
+    for group, methods in groups.items():
+        class_name = f"{group.title()}Api"
+        body = '\n\n'.join('\n'.join(method) for method in methods)
+        classes.append(
+            f"\n\nclass {class_name}:\n\n"
+            f"    def __init__(self, client: httpx.AsyncClient):\n"
+            f"        self._client = client\n\n"
+            f"{body}\n"
+        )
+    group_fields = '\n'.join(f"        self.{group} = {group.title()}Api(self._client)" for group in groups)
+
+    # 标准库与第三方库分为两组导入
+    pydantic_names = renderer.imports.pop('pydantic')
+    imports = [
+        f"from {module} import {', '.join(sorted(renderer.imports[module]))}" for module in sorted(renderer.imports)
+    ]
+    imports += ['', 'import httpx', f"from pydantic import {', '.join(sorted(pydantic_names))}"]
+    models = ''.join('\n\n' + '\n'.join(lines) + '\n' for lines in renderer.models.values())
+    client = (
+            HEADER + '\n'.join(imports) + '\n' + RESULT + models + RUNTIME + ''.join(classes)
+            + CLIENT.format(groups=group_fields)
+    )
+
+    names = ['Result', *(model.__name__ for model in renderer.models), 'We0IndexApiError', 'We0IndexClient']
+    init = (
+            HEADER + 'from .client import (\n' + ''.join(f"    {name},\n" for name in names) + ')\n\n'
+            + '__all__ = [\n' + ''.join(f"    '{name}',\n" for name in names) + ']\n'
+    )
+    return {OUTPUT_PATH: client, INIT_PATH: init}
+
+
+def check() -> bool:
+    up_to_date = True
+    for path, content in generate().items():
+        try:
+            with open(path, encoding='utf-8') as f:
+                current = f.read()
+        except FileNotFoundError:
+            current = None
+        if current != content:
+            print(f"{path} is out of date, run: python scripts/generate_client.py", file=sys.stderr)
+            up_to_date = False
+    return up_to_date
+
+
+def main():
+    if '--check' in sys.argv[1:]:
+        sys.exit(0 if check() else 1)
+    os.makedirs(OUTPUT_DIR, exist_ok=True)
+    for path, content in generate().items():
+        with open(path, 'w', encoding='utf-8') as f:
+            f.write(content)
+        print(f"Generated {path}")
+
+
+if __name__ == '__main__':
+    main()
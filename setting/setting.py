@@ -6,29 +6,235 @@
 # @File    : setting
 # @Software: PyCharm
 import os.path
-from functools import lru_cache
-from typing import Type
+from ipaddress import ip_network
+from typing import Type, Dict, List, Literal
 
 from pydantic import BaseModel, Field, model_validator
 from pydantic_settings import BaseSettings, SettingsConfigDict, PydanticBaseSettingsSource, YamlConfigSettingsSource
 
 from constants.constants import Constants
 from domain.enums.chroma_mode import ChromaMode
+from domain.enums.maintenance_mode import MaintenanceMode
 from domain.enums.model_provider import ModelType
 from domain.enums.qdrant_mode import QdrantMode
+from domain.enums.rate_limit_mode import RateLimitMode
 from domain.enums.vector_type import VectorType
 
 
+class TimeoutSettings(BaseModel):
+    default: float = Field(default=120)
+    routes: Dict[str, float] = Field(default_factory=dict)
+
+
+class BodyLimitSettings(BaseModel):
+    default: int = Field(default=16 * 1024 * 1024)  # 字节
+    routes: Dict[str, int] = Field(default_factory=dict)
+
+
+class CompressionSettings(BaseModel):
+    enabled: bool = Field(default=True)
+    minimum_size: int = Field(default=1024, ge=0, alias='minimum-size')
+    level: int = Field(default=6, ge=1, le=9)
+
+
+class LoadShedSettings(BaseModel):
+    max_in_flight: int = Field(default=0, ge=0, alias='max-in-flight')  # 0 表示不限制
+    retry_after: int = Field(default=1, ge=1, alias='retry-after')  # 秒
+    exempt: List[str] = Field(default_factory=lambda: ['/health', '/healthz', '/readyz', '/metrics'])
+
+
+class StartupSettings(BaseModel):
+    timeout: float = Field(default=120, gt=0)  # 秒，全部依赖初始化的总时限
+    dependency_timeout: float = Field(default=90, gt=0, alias='dependency-timeout')  # 秒，单个依赖的默认时限
+    dependencies: Dict[str, float] = Field(default_factory=dict)  # 按依赖名称覆盖时限，如 vector
+
+    def get_timeout(self, name: str) -> float:
+        return self.dependencies.get(name, self.dependency_timeout)
+
+
+class CacheControlSettings(BaseModel):
+    # 允许公开缓存的 GET 接口及其 max-age（秒），其余响应均为 private, no-store
+    # 数据接口均为 POST，默认只有文档相关页面可缓存，需要 server.docs 开启
+    routes: Dict[str, int] = Field(default_factory=lambda: {'/openapi.json': 300, '/docs': 300, '/redoc': 300})
+
+
+class TLSSettings(BaseModel):
+    cert: str | None = Field(default=None)
+    key: str | None = Field(default=None)
+    # 仅保留支持前向保密的 AEAD 套件；TLS 1.3 套件由 OpenSSL 单独管理，不受此项影响
+    ciphers: str = Field(default='ECDHE+AESGCM:ECDHE+CHACHA20:!aNULL:!MD5:!DSS')
+
+    @property
+    def enabled(self) -> bool:
+        return bool(self.cert and self.key)
+
+    @model_validator(mode='after')
+    def check_pair(self):
+        if bool(self.cert) != bool(self.key):
+            raise ValueError('tls.cert and tls.key must be set together')
+        return self
+
+
 class ServerSettings(BaseModel):
     host: str = Field('0.0.0.0')
     port: int = Field(8080)
     reload: bool = Field(True)
+    docs: bool = Field(False)  # 默认关闭 /docs、/redoc 与 /openapi.json，开发环境在配置中开启
+    shutdown_delay: float = Field(default=0, ge=0, alias='shutdown-delay')  # 秒，退出前先摘流的等待时间
+    # 信任的反向代理地址，request.client 取自其 X-Forwarded-For，限流与维护模式白名单据此识别客户端 IP
+    forwarded_allow_ips: str | None = Field(default=None, alias='forwarded-allow-ips')
+    legacy_sunset: str | None = Field(default=None, alias='legacy-sunset')  # 无版本前缀旧路径的下线时间（HTTP-date）
+    debug: bool = Field(False)  # 为 true 时未处理异常的响应中附带异常类型与堆栈，仅用于本地开发
+    timeout: TimeoutSettings = Field(default_factory=TimeoutSettings)
+    compression: CompressionSettings = Field(default_factory=CompressionSettings)
+    body_limit: BodyLimitSettings = Field(default_factory=BodyLimitSettings, alias='body-limit')
+    load_shed: LoadShedSettings = Field(default_factory=LoadShedSettings, alias='load-shed')
+    startup: StartupSettings = Field(default_factory=StartupSettings)
+    cache_control: CacheControlSettings = Field(default_factory=CacheControlSettings, alias='cache-control')
+    tls: TLSSettings = Field(default_factory=TLSSettings)
+
+
+class RateLimitTierSettings(BaseModel):
+    per_minute: int = Field(gt=0, alias='per-minute')
+    burst: int = Field(gt=0)
+
+
+class RateLimitSettings(BaseModel):
+    enabled: bool = Field(default=False)
+    # 未携带 API Key 的请求按客户端 IP 使用默认限额
+    per_minute: int = Field(default=60, gt=0, alias='per-minute')
+    burst: int = Field(default=60, gt=0)
+    exempt: List[str] = Field(default_factory=lambda: ['/health', '/healthz', '/readyz', '/metrics'])
+    tiers: Dict[str, RateLimitTierSettings] = Field(default_factory=dict)
+    keys: Dict[str, str] = Field(default_factory=dict)  # API Key 名称 -> tier，未列出的 key 使用默认限额
+    exempt_keys: List[str] = Field(default_factory=list, alias='exempt-keys')  # 不限流的 API Key 名称，如内部服务
+    # shadow 模式下超限只记录日志并设置 X-RateLimit-Exceeded 响应头，不拒绝请求
+    mode: RateLimitMode = Field(default=RateLimitMode.ENFORCE)
+    routes: Dict[str, RateLimitMode] = Field(default_factory=dict)  # 按路径前缀覆盖 mode，最长前缀优先
+    # 按路径前缀设置单次请求消耗的令牌数，最长前缀优先，未匹配的路径消耗 1 个
+    costs: Dict[str, int] = Field(default_factory=dict)
+
+    def get_mode(self, path: str) -> RateLimitMode:
+        for prefix in sorted(self.routes, key=len, reverse=True):
+            if path.startswith(prefix):
+                return self.routes[prefix]
+        return self.mode
+
+    def get_cost(self, path: str) -> int:
+        for prefix in sorted(self.costs, key=len, reverse=True):
+            if path.startswith(prefix):
+                return self.costs[prefix]
+        return 1
+
+    def get_limits(self, key_name: str | None) -> tuple[int, int]:
+        """返回 (per_minute, burst)"""
+        tier = self.tiers.get(self.keys.get(key_name)) if key_name else None
+        if tier is None:
+            return self.per_minute, self.burst
+        return tier.per_minute, tier.burst
+
+    @model_validator(mode='after')
+    def check_tiers(self):
+        for key_name, tier in self.keys.items():
+            if tier not in self.tiers:
+                raise ValueError(f"rate-limit.keys.{key_name}: unknown tier {tier}")
+        # 消耗超过桶容量的请求永远无法通过
+        min_burst = min([self.burst, *(tier.burst for tier in self.tiers.values())])
+        for prefix, cost in self.costs.items():
+            if not 1 <= cost <= min_burst:
+                raise ValueError(f"rate-limit.costs.{prefix}: must be between 1 and the smallest burst ({min_burst})")
+        return self
+
+
+class MaintenanceSettings(BaseModel):
+    mode: MaintenanceMode = Field(default=MaintenanceMode.OFF)
+    retry_after: int = Field(default=300, ge=1, alias='retry-after')  # 秒
+    allow_ips: List[str] = Field(default_factory=list, alias='allow-ips')  # 支持 CIDR
+    exempt: List[str] = Field(default_factory=lambda: ['/health', '/healthz', '/readyz', '/metrics'])
+    # read-only 模式下仍允许的 POST 接口
+    read_routes: List[str] = Field(
+        default_factory=lambda: ['/vector/retrieval', '/vector/all_index'],
+        alias='read-routes'
+    )
+
+    @model_validator(mode='after')
+    def check_allow_ips(self):
+        for network in self.allow_ips:
+            ip_network(network, strict=False)
+        return self
+
+
+class ApiKeySettings(BaseModel):
+    name: str
+    hash: str  # API Key 的 SHA-256 摘要，不保存明文
+    scopes: List[str] = Field(default_factory=lambda: ['*'])
+
+
+class AuthSettings(BaseModel):
+    api_keys: List[ApiKeySettings] = Field(default_factory=list, alias='api-keys')
+    exempt: List[str] = Field(
+        default_factory=lambda: ['/health', '/healthz', '/readyz', '/metrics', '/docs', '/redoc', '/openapi.json']
+    )
+
+
+class HealthSettings(BaseModel):
+    cache_ttl: float = Field(default=2, ge=0, alias='cache-ttl')  # 秒，/readyz 复用检查结果的时长
+    timeout: float = Field(default=3, gt=0)  # 秒，单项依赖检查的超时
+
+
+class TracingSettings(BaseModel):
+    enabled: bool = Field(default=False)
+    endpoint: str = Field(default='http://localhost:4318/v1/traces')
+    service_name: str = Field(default='we0-index', alias='service-name')
+
+
+class HttpClientSettings(BaseModel):
+    timeout: float = Field(default=300, gt=0)  # 秒，单次尝试的读写超时
+    connect_timeout: float = Field(default=10, gt=0, alias='connect-timeout')
+    retries: int = Field(default=3, ge=0)  # 仅针对网络错误与 5xx
+    backoff: float = Field(default=0.5, ge=0)  # 秒，指数退避的基数
+    max_backoff: float = Field(default=10, ge=0, alias='max-backoff')
+    max_connections: int = Field(default=100, gt=0, alias='max-connections')
+    max_keepalive_connections: int = Field(default=20, ge=0, alias='max-keepalive-connections')
+
+
+class CorsPolicySettings(BaseModel):
+    allowed_origins: List[str] = Field(default_factory=lambda: ['*'], alias='allowed-origins')
+    allow_methods: List[str] = Field(default_factory=lambda: ['*'], alias='allow-methods')
+    allow_headers: List[str] = Field(default_factory=lambda: ['*'], alias='allow-headers')
+    allow_credentials: bool = Field(default=False, alias='allow-credentials')
+    expose_headers: List[str] = Field(default_factory=lambda: [Constants.Header.REQUEST_ID], alias='expose-headers')
+    max_age: int = Field(default=600, ge=0, alias='max-age')
+
+    @model_validator(mode='after')
+    def check_credentials(self):
+        # 携带凭证时浏览器不接受 *，直接拒绝这种无法生效的配置
+        if self.allow_credentials and '*' in self.allowed_origins:
+            raise ValueError("allowed-origins must not contain '*' when allow-credentials is enabled")
+        return self
+
+
+class CorsSettings(CorsPolicySettings):
+    # 按路径前缀（不含版本前缀）覆盖全局策略，最长前缀优先；未匹配的路径使用全局策略
+    routes: Dict[str, CorsPolicySettings] = Field(default_factory=dict)
 
 
 class LogSettings(BaseModel):
     level: str = Field(default="INFO")
     file: bool = Field(default=False)
     debug: bool = Field(default=False)
+    format: Literal['text', 'json'] = Field(default='text')  # json 便于日志平台按字段检索，修改后需重启
+    # 2xx 访问日志的采样比例，4xx/5xx 与慢请求始终记录
+    access_sample_rate: float = Field(default=1.0, ge=0, le=1, alias='access-sample-rate')
+    slow_request_threshold: float = Field(default=1.0, ge=0, alias='slow-request-threshold')  # 秒
+
+    @model_validator(mode='after')
+    def check_level(self):
+        # 启动与 SIGHUP 热更新共用该校验，非法级别不会进入配置快照
+        self.level = self.level.upper()
+        if self.level not in Constants.Log.LEVELS:
+            raise ValueError(f"log.level: must be one of {', '.join(Constants.Log.LEVELS)}, got {self.level}")
+        return self
 
 
 class PGVectorSettings(BaseSettings):
@@ -36,7 +242,34 @@ class PGVectorSettings(BaseSettings):
     host: str
     port: int
     user: str
-    password: str
+    password: str = Field(default='')
+    # 每次建立新连接时读取的密码文件，优先于 password；替换文件内容后发送 SIGHUP 完成凭证轮换
+    password_file: str | None = Field(default=None)
+    sslmode: Literal['disable', 'allow', 'prefer', 'require', 'verify-ca', 'verify-full'] = Field(default='prefer')
+    sslrootcert: str | None = Field(default=None)  # verify-ca / verify-full 校验服务端证书所用的 CA
+    max_open_conns: int = Field(default=15)
+    max_idle_conns: int = Field(default=5)
+    conn_max_lifetime: int = Field(default=1800)  # 秒，-1 表示不回收
+    connect_retries: int = Field(default=5)
+    query_timeout: float = Field(default=30)  # 秒，<= 0 表示不限制
+    slow_query_threshold: float = Field(default=1)  # 秒，超过该耗时的语句记录告警日志
+
+    @property
+    def tls_required(self) -> bool:
+        return self.sslmode in ('require', 'verify-ca', 'verify-full')
+
+    def get_password(self) -> str:
+        if self.password_file:
+            with open(self.password_file, encoding='utf-8') as f:
+                return f.read().strip()
+        return self.password
+
+    @model_validator(mode='after')
+    def check_pool(self):
+        # sqlalchemy 中 pool_size=0 表示不限制连接数，因此空闲连接数至少为 1
+        if not 1 <= self.max_idle_conns <= self.max_open_conns:
+            raise ValueError('max_idle_conns must be between 1 and max_open_conns')
+        return self
 
 
 class QdrantDiskSettings(BaseSettings):
@@ -103,6 +336,7 @@ class VectorSettings(BaseSettings):
     chat_model: str = Field(default='gpt-4o-mini', alias='chat-model')
     embedding_provider: ModelType = Field(default='openai', alias='embedding-provider')
     embedding_model: str = Field(default='text-embedding-3-small', alias='embedding-model')
+    health_check_interval: float = Field(default=30, alias='health-check-interval')  # 秒，<= 0 表示不巡检
     pgvector: PGVectorSettings | None
     qdrant: QdrantSettings | None
     chroma: ChromaSettings | None
@@ -118,6 +352,13 @@ class We0IndexSettings(BaseModel):
     application: str
     log: LogSettings
     server: ServerSettings
+    cors: CorsSettings = Field(default_factory=CorsSettings)
+    auth: AuthSettings = Field(default_factory=AuthSettings)
+    rate_limit: RateLimitSettings = Field(default_factory=RateLimitSettings, alias='rate-limit')
+    tracing: TracingSettings = Field(default_factory=TracingSettings)
+    health: HealthSettings = Field(default_factory=HealthSettings)
+    maintenance: MaintenanceSettings = Field(default_factory=MaintenanceSettings)
+    http_client: HttpClientSettings = Field(default_factory=HttpClientSettings, alias='http-client')
     vector: VectorSettings
 
 
@@ -148,10 +389,46 @@ class AppSettings(BaseSettings):
         )
 
 
-@lru_cache
+# 运行期可热更新的配置段，其余配置变更需重启生效
+RELOADABLE_SECTIONS = ('log', 'rate_limit', 'cors', 'maintenance', 'auth')
+
+_settings: We0IndexSettings | None = None
+
+
 def get_we0_index_settings() -> We0IndexSettings:
-    app_settings = AppSettings()
-    return app_settings.we0_index
+    global _settings
+    if _settings is None:
+        _settings = AppSettings().we0_index
+    return _settings
+
+
+def reload_we0_index_settings() -> tuple[We0IndexSettings, List[str]]:
+    """
+    重新读取配置文件，仅替换 RELOADABLE_SECTIONS 中的配置段
+    新快照整体替换旧快照，读取方总能拿到一致的配置
+    返回新配置以及需要重启才能生效的配置段；新配置未通过校验时抛出 ConfigurationError，保留当前配置
+    """
+    # config.validation 依赖本模块，在函数内导入避免循环导入
+    from config.validation import ConfigurationError, collect_problems
+    global _settings
+    current = get_we0_index_settings()
+    loaded = AppSettings().we0_index
+    if loaded is None:
+        raise ValueError('we0-index settings not found')
+    reloadable = list(RELOADABLE_SECTIONS)
+    if bool(loaded.auth.api_keys) != bool(current.auth.api_keys):
+        # 鉴权中间件与管理接口在启动时按是否配置了 key 挂载，开启或关闭鉴权需重启，热更新只替换 key 列表
+        reloadable.remove('auth')
+    ignored = [
+        name for name in We0IndexSettings.model_fields
+        if name not in reloadable and getattr(loaded, name) != getattr(current, name)
+    ]
+    candidate = current.model_copy(update={name: getattr(loaded, name) for name in reloadable})
+    problems = collect_problems(candidate)
+    if problems:
+        raise ConfigurationError(problems)
+    _settings = candidate
+    return _settings, ignored
 
 
 if __name__ == '__main__':
@@ -0,0 +1,39 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : asgi
+# @Software: PyCharm
+from typing import List, Tuple
+
+from starlette.types import ASGIApp, Message
+
+
+async def call(app: ASGIApp, method: str, path: str, client: Tuple[str, int] = ('127.0.0.1', 50000)) -> Tuple[int, List[Tuple[bytes, bytes]]]:
+    """不经过网络直接调用 ASGI 应用，返回状态码与响应头，便于高并发场景下批量发起请求"""
+    scope = {
+        'type': 'http',
+        'asgi': {'version': '3.0'},
+        'http_version': '1.1',
+        'method': method,
+        'scheme': 'http',
+        'path': path,
+        'raw_path': path.encode(),
+        'query_string': b'',
+        'root_path': '',
+        'headers': [(b'host', b'testserver')],
+        'client': client,
+        'server': ('testserver', 80),
+    }
+    messages: List[Message] = []
+
+    async def receive() -> Message:
+        return {'type': 'http.request', 'body': b'', 'more_body': False}
+
+    async def send(message: Message) -> None:
+        messages.append(message)
+
+    await app(scope, receive, send)
+    start = next(message for message in messages if message['type'] == 'http.response.start')
+    return start['status'], start['headers']
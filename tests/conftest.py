@@ -0,0 +1,19 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : conftest
+# @Software: PyCharm
+import pytest
+
+import setting.setting as setting_module
+from setting.setting import We0IndexSettings, get_we0_index_settings
+
+
+@pytest.fixture
+def settings(monkeypatch) -> We0IndexSettings:
+    """基于 resource 中的配置生成副本，用例可随意修改，结束后恢复原配置"""
+    snapshot = get_we0_index_settings().model_copy(deep=True)
+    monkeypatch.setattr(setting_module, '_settings', snapshot)
+    return snapshot
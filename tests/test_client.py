@@ -0,0 +1,125 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : test_client
+# @Software: PyCharm
+import ast
+import os
+import sys
+from typing import List
+
+import httpx
+import pytest
+
+from clients.we0_index import (
+    DocumentMeta,
+    DropIndexRequest,
+    RetrievalRequest,
+    We0IndexApiError,
+    We0IndexClient,
+)
+from constants.constants import Constants
+from domain.entity.document import Document, DocumentMeta as ServerDocumentMeta
+from extensions.ext_manager import ExtManager
+from launch.launch import app
+from scripts import generate_client
+
+
+class FakeEmbeddingModel:
+
+    async def create_embedding(self, texts: List[str]) -> List[List[float]]:
+        return [[0.0, 1.0] for _ in texts]
+
+
+class FakeVector:
+    """替代 ExtManager.vector，不依赖真实的向量库与 Embedding 服务"""
+
+    def __init__(self):
+        self.dropped: List[str] = []
+        self.error: Exception | None = None
+
+    async def drop(self, repo_id: str):
+        if self.error:
+            raise self.error
+        self.dropped.append(repo_id)
+
+    async def get_embedding_model(self) -> FakeEmbeddingModel:
+        return FakeEmbeddingModel()
+
+    async def search_by_vector(self, repo_id: str, file_ids, query_vector) -> List[Document]:
+        return [Document(meta=ServerDocumentMeta(
+            repo_id=repo_id,
+            file_id='file',
+            segment_id='segment',
+            relative_path='src/main.py',
+            start_line=1,
+            end_line=10,
+            segment_block=0,
+            segment_hash='hash',
+            score=0.9,
+        ))]
+
+
+@pytest.fixture
+def vector(monkeypatch) -> FakeVector:
+    fake = FakeVector()
+    monkeypatch.setattr(ExtManager, 'vector', fake)
+    return fake
+
+
+@pytest.fixture
+async def client():
+    # ASGITransport 在进程内调用完整的应用，经过全部中间件与异常处理器
+    async with We0IndexClient('http://testserver', transport=httpx.ASGITransport(app=app)) as client:
+        yield client
+
+
+def test_generated_client_is_up_to_date():
+    assert generate_client.check(), 'run: python scripts/generate_client.py'
+
+
+def test_generated_client_does_not_import_server_code():
+    path = os.path.join(Constants.Path.ROOT_PATH, 'clients', 'we0_index', 'client.py')
+    with open(path, encoding='utf-8') as f:
+        tree = ast.parse(f.read())
+    modules = {alias.name for node in ast.walk(tree) if isinstance(node, ast.Import) for alias in node.names}
+    modules |= {node.module for node in ast.walk(tree) if isinstance(node, ast.ImportFrom)}
+    third_party = {module.split('.')[0] for module in modules} - set(sys.stdlib_module_names)
+    assert third_party == {'httpx', 'pydantic'}
+
+
+async def test_drop_index(client, vector):
+    result = await client.vector.drop_index(DropIndexRequest(repo_id='repo'))
+    assert result.success
+    assert result.request_id
+    assert vector.dropped == ['repo']
+
+
+async def test_retrieval(client, vector):
+    result = await client.vector.retrieval(RetrievalRequest(repo_id='repo', query='where is main'))
+    assert result.success
+    assert len(result.data) == 1
+    assert isinstance(result.data[0], DocumentMeta)
+    assert result.data[0].relative_path == 'src/main.py'
+    assert result.data[0].score == 0.9
+
+
+async def test_validation_error_raises(client, vector):
+    # 客户端模型不做取值校验，由服务端返回 422
+    with pytest.raises(We0IndexApiError) as e:
+        await client.vector.retrieval(RetrievalRequest(repo_id='repo', query=''))
+    assert e.value.status_code == 422
+    assert e.value.result is not None
+    assert not e.value.result.success
+
+
+async def test_unknown_error_is_not_leaked(client, vector):
+    vector.error = RuntimeError('connection to postgresql://user:secret@db failed')
+    with pytest.raises(We0IndexApiError) as e:
+        await client.vector.drop_index(DropIndexRequest(repo_id='repo'))
+    assert e.value.status_code == 500
+    assert e.value.result.message == 'Internal Server Error'
+    assert e.value.result.request_id
+    assert 'secret' not in str(e.value)
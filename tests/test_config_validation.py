@@ -0,0 +1,148 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : test_config_validation
+# @Software: PyCharm
+import copy
+import hashlib
+
+import pytest
+from pydantic import ValidationError
+
+import config.validation as validation
+from config.validation import ConfigurationError, collect_problems, load_settings
+from setting.setting import LogSettings, We0IndexSettings
+
+VALID_HASH = hashlib.sha256(b'secret').hexdigest()
+
+BASE_CONFIG = {
+    'application': 'we0-index',
+    'log': {},
+    'server': {},
+    'vector': {
+        'platform': 'pgvector',
+        'pgvector': {'db': 'we0_index', 'host': 'localhost', 'port': 5432, 'user': 'postgres'},
+    },
+}
+
+
+def build_settings(**overrides) -> We0IndexSettings:
+    """overrides 以 'a.b.c' 为 key 覆盖 BASE_CONFIG 中的字段"""
+    config = copy.deepcopy(BASE_CONFIG)
+    for path, value in overrides.items():
+        *parents, name = path.split('.')
+        node = config
+        for parent in parents:
+            node = node.setdefault(parent, {})
+        node[name] = value
+    return We0IndexSettings.model_validate(config)
+
+
+def test_valid_settings_have_no_problems():
+    assert collect_problems(build_settings()) == []
+
+
+@pytest.mark.parametrize('overrides, expected', [
+    ({'server.port': 0}, 'server.port: must be between 1 and 65535, got 0'),
+    ({'server.port': 70000}, 'server.port: must be between 1 and 65535, got 70000'),
+    (
+        {'server.tls': {'cert': '/nonexistent/cert.pem', 'key': '/nonexistent/key.pem'}},
+        'server.tls.cert: file not found: /nonexistent/cert.pem',
+    ),
+    (
+        {'server.tls': {'cert': '/nonexistent/cert.pem', 'key': '/nonexistent/key.pem'}},
+        'server.tls.key: file not found: /nonexistent/key.pem',
+    ),
+    (
+        {'auth.api-keys': [{'name': 'ci', 'hash': VALID_HASH}, {'name': 'ci', 'hash': VALID_HASH}]},
+        'auth.api-keys: duplicate name: ci',
+    ),
+    (
+        {'auth.api-keys': [{'name': 'ci', 'hash': 'not-a-digest'}]},
+        'auth.api-keys.ci.hash: must be a lowercase hex SHA-256 digest',
+    ),
+    (
+        {'auth.api-keys': [{'name': 'ci', 'hash': VALID_HASH.upper()}]},
+        'auth.api-keys.ci.hash: must be a lowercase hex SHA-256 digest',
+    ),
+    (
+        {'vector': {'platform': 'qdrant', 'qdrant': None}},
+        'vector.qdrant: configuration is missing for platform qdrant',
+    ),
+    ({'vector.pgvector.host': ''}, 'vector.pgvector.host: must not be empty'),
+    ({'vector.pgvector.db': ''}, 'vector.pgvector.db: must not be empty'),
+    ({'vector.pgvector.user': ''}, 'vector.pgvector.user: must not be empty'),
+    ({'vector.pgvector.port': 0}, 'vector.pgvector.port: must be between 1 and 65535, got 0'),
+    (
+        {'vector.pgvector.password_file': '/nonexistent/password'},
+        'vector.pgvector.password_file: file not found: /nonexistent/password',
+    ),
+    (
+        {'vector.pgvector.sslmode': 'verify-full'},
+        'vector.pgvector.sslrootcert: required when sslmode is verify-full',
+    ),
+    (
+        {'vector.pgvector.sslmode': 'verify-ca'},
+        'vector.pgvector.sslrootcert: required when sslmode is verify-ca',
+    ),
+    (
+        {'vector.pgvector.sslrootcert': '/nonexistent/ca.pem'},
+        'vector.pgvector.sslrootcert: file not found: /nonexistent/ca.pem',
+    ),
+])
+def test_collect_problems(overrides, expected):
+    assert expected in collect_problems(build_settings(**overrides))
+
+
+def test_collect_problems_reports_every_problem():
+    settings = build_settings(**{'server.port': 0, 'vector.pgvector.host': '', 'vector.pgvector.port': 0})
+    assert len(collect_problems(settings)) == 3
+
+
+def test_existing_files_are_accepted(tmp_path):
+    cert, key, ca = tmp_path / 'cert.pem', tmp_path / 'key.pem', tmp_path / 'ca.pem'
+    for path in (cert, key, ca):
+        path.write_text('')
+    settings = build_settings(**{
+        'server.tls': {'cert': str(cert), 'key': str(key)},
+        'vector.pgvector.sslmode': 'verify-full',
+        'vector.pgvector.sslrootcert': str(ca),
+    })
+    assert collect_problems(settings) == []
+
+
+@pytest.mark.parametrize('level', ['VERBOSE', 'warn', ''])
+def test_log_level_rejected(level):
+    with pytest.raises(ValidationError, match='log.level'):
+        LogSettings(level=level)
+
+
+def test_log_level_normalized():
+    assert LogSettings(level='debug').level == 'DEBUG'
+
+
+def test_load_settings_raises_configuration_error(monkeypatch):
+    monkeypatch.setattr(validation, 'get_we0_index_settings', lambda: build_settings(**{'server.port': 0}))
+    with pytest.raises(ConfigurationError) as e:
+        load_settings()
+    assert e.value.problems == ['server.port: must be between 1 and 65535, got 0']
+
+
+def test_load_settings_converts_validation_error(monkeypatch):
+    def invalid_settings():
+        return build_settings(**{'log.level': 'VERBOSE'})
+
+    monkeypatch.setattr(validation, 'get_we0_index_settings', invalid_settings)
+    with pytest.raises(ConfigurationError) as e:
+        load_settings()
+    assert len(e.value.problems) == 1
+    assert e.value.problems[0].startswith('log:')
+
+
+def test_load_settings_reports_missing_section(monkeypatch):
+    monkeypatch.setattr(validation, 'get_we0_index_settings', lambda: None)
+    with pytest.raises(ConfigurationError) as e:
+        load_settings()
+    assert e.value.problems == ['we0-index: section is missing']
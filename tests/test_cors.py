@@ -0,0 +1,89 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : test_cors
+# @Software: PyCharm
+import pytest
+from starlette.applications import Starlette
+from starlette.responses import PlainTextResponse
+from starlette.routing import Route
+from starlette.testclient import TestClient
+
+from middleware.cors import ReloadableCORSMiddleware
+from setting.setting import CorsSettings
+
+ALLOWED_ORIGIN = 'https://app.example.com'
+ADMIN_ORIGIN = 'https://admin.example.com'
+
+
+async def ok(request):
+    return PlainTextResponse('ok')
+
+
+@pytest.fixture
+def client(settings) -> TestClient:
+    settings.cors = CorsSettings.model_validate({
+        'allowed-origins': [ALLOWED_ORIGIN, 'https://*.partner.example.com'],
+        'allow-methods': ['GET', 'POST'],
+        'max-age': 600,
+        'routes': {
+            '/admin': {'allowed-origins': [ADMIN_ORIGIN], 'allow-methods': ['GET'], 'max-age': 60},
+        },
+    })
+    app = Starlette(routes=[
+        Route('/vector/retrieval', ok, methods=['POST']),
+        Route('/v1/vector/retrieval', ok, methods=['POST']),
+        Route('/admin/loglevel', ok),
+        Route('/v1/admin/loglevel', ok),
+    ])
+    app.add_middleware(ReloadableCORSMiddleware)
+    return TestClient(app)
+
+
+def preflight(client: TestClient, path: str, origin: str, method: str = 'POST'):
+    return client.options(path, headers={'Origin': origin, 'Access-Control-Request-Method': method})
+
+
+def test_preflight_from_allowed_origin(client):
+    response = preflight(client, '/v1/vector/retrieval', ALLOWED_ORIGIN)
+    assert response.status_code == 200
+    assert response.headers['access-control-allow-origin'] == ALLOWED_ORIGIN
+    assert response.headers['access-control-max-age'] == '600'
+
+
+def test_preflight_from_wildcard_subdomain(client):
+    origin = 'https://eu.partner.example.com'
+    response = preflight(client, '/v1/vector/retrieval', origin)
+    assert response.status_code == 200
+    assert response.headers['access-control-allow-origin'] == origin
+
+
+def test_preflight_from_disallowed_origin(client):
+    response = preflight(client, '/v1/vector/retrieval', 'https://evil.example.org')
+    assert response.status_code == 400
+    assert 'access-control-allow-origin' not in response.headers
+
+
+def test_simple_request_carries_cors_headers(client):
+    response = client.post('/v1/vector/retrieval', headers={'Origin': ALLOWED_ORIGIN})
+    assert response.status_code == 200
+    assert response.headers['access-control-allow-origin'] == ALLOWED_ORIGIN
+
+
+@pytest.mark.parametrize('path', ['/admin/loglevel', '/v1/admin/loglevel'])
+def test_route_policy_on_unversioned_path(client, path):
+    response = preflight(client, path, ADMIN_ORIGIN, method='GET')
+    assert response.status_code == 200
+    assert response.headers['access-control-allow-origin'] == ADMIN_ORIGIN
+    assert response.headers['access-control-max-age'] == '60'
+
+    # 全局策略允许的来源不适用于 /admin
+    assert preflight(client, path, ALLOWED_ORIGIN, method='GET').status_code == 400
+
+
+def test_route_policy_rebuilt_after_reload(client, settings):
+    assert preflight(client, '/v1/vector/retrieval', ADMIN_ORIGIN).status_code == 400
+    settings.cors = CorsSettings.model_validate({'allowed-origins': [ADMIN_ORIGIN]})
+    assert preflight(client, '/v1/vector/retrieval', ADMIN_ORIGIN).status_code == 200
@@ -0,0 +1,139 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : test_disconnect
+# @Software: PyCharm
+import asyncio
+import json
+from typing import List
+
+import pytest
+from prometheus_client import REGISTRY
+from starlette.types import Message, Receive, Scope, Send
+
+from middleware.disconnect import CLIENT_CLOSED_REQUEST, DisconnectMiddleware
+
+SCOPE = {'type': 'http', 'method': 'POST', 'path': '/v1/vector/retrieval', 'headers': []}
+
+
+class FakeClient:
+    """请求体发送完毕后阻塞在 receive 上，调用 disconnect 后返回 http.disconnect"""
+
+    def __init__(self, body: bytes = b'{}'):
+        self.body = body
+        self.body_sent = False
+        self.disconnected = asyncio.Event()
+        self.messages: List[Message] = []
+
+    async def receive(self) -> Message:
+        if not self.body_sent:
+            self.body_sent = True
+            return {'type': 'http.request', 'body': self.body, 'more_body': False}
+        await self.disconnected.wait()
+        return {'type': 'http.disconnect'}
+
+    async def send(self, message: Message) -> None:
+        self.messages.append(message)
+
+    def disconnect(self) -> None:
+        self.disconnected.set()
+
+    @property
+    def status(self) -> int | None:
+        return next((message['status'] for message in self.messages if message['type'] == 'http.response.start'), None)
+
+
+def cancelled_total() -> float:
+    return REGISTRY.get_sample_value('we0_index_http_requests_cancelled_total') or 0
+
+
+class SlowApp:
+    """读取请求体后一直等待，记录是否被取消"""
+
+    def __init__(self, read_body: bool = True, start_response: bool = False):
+        self.read_body = read_body
+        self.start_response = start_response
+        self.started = asyncio.Event()
+        self.cancelled = False
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send) -> None:
+        if self.read_body:
+            await receive()
+        if self.start_response:
+            await send({'type': 'http.response.start', 'status': 200, 'headers': []})
+        self.started.set()
+        try:
+            await asyncio.sleep(60)
+        except asyncio.CancelledError:
+            self.cancelled = True
+            raise
+
+
+async def test_disconnect_cancels_handler_and_returns_499():
+    app, client = SlowApp(), FakeClient()
+    before = cancelled_total()
+    task = asyncio.create_task(DisconnectMiddleware(app)(dict(SCOPE), client.receive, client.send))
+    await app.started.wait()
+    client.disconnect()
+    await asyncio.wait_for(task, timeout=1)
+
+    assert app.cancelled
+    assert client.status == CLIENT_CLOSED_REQUEST
+    body = json.loads(client.messages[-1]['body'])
+    assert body['code'] == CLIENT_CLOSED_REQUEST
+    assert cancelled_total() == before + 1
+
+
+async def test_disconnect_after_response_started_sends_nothing_more():
+    app, client = SlowApp(start_response=True), FakeClient()
+    task = asyncio.create_task(DisconnectMiddleware(app)(dict(SCOPE), client.receive, client.send))
+    await app.started.wait()
+    client.disconnect()
+    await asyncio.wait_for(task, timeout=1)
+
+    assert app.cancelled
+    assert client.status == 200
+    assert len(client.messages) == 1
+
+
+async def test_completed_request_is_not_cancelled():
+    async def app(scope: Scope, receive: Receive, send: Send) -> None:
+        await receive()
+        await send({'type': 'http.response.start', 'status': 200, 'headers': []})
+        await send({'type': 'http.response.body', 'body': b'ok'})
+
+    client = FakeClient()
+    before = cancelled_total()
+    await asyncio.wait_for(DisconnectMiddleware(app)(dict(SCOPE), client.receive, client.send), timeout=1)
+
+    assert client.status == 200
+    assert client.messages[-1]['body'] == b'ok'
+    assert cancelled_total() == before
+
+
+async def test_disconnect_is_not_watched_before_body_is_read():
+    # 路由未读取请求体时不监听断开，避免与路由读取请求体竞争
+    app, client = SlowApp(read_body=False), FakeClient()
+    task = asyncio.create_task(DisconnectMiddleware(app)(dict(SCOPE), client.receive, client.send))
+    await app.started.wait()
+    client.disconnect()
+    await asyncio.sleep(0.05)
+
+    assert not task.done()
+    task.cancel()
+    with pytest.raises(asyncio.CancelledError):
+        await task
+
+
+async def test_handler_cancelled_for_other_reasons_propagates():
+    app, client = SlowApp(), FakeClient()
+    task = asyncio.create_task(DisconnectMiddleware(app)(dict(SCOPE), client.receive, client.send))
+    await app.started.wait()
+    task.cancel()
+    with pytest.raises(asyncio.CancelledError):
+        await task
+
+    assert app.cancelled
+    assert client.status is None
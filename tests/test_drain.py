@@ -0,0 +1,75 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : test_drain
+# @Software: PyCharm
+import pytest
+from starlette.applications import Starlette
+from starlette.responses import PlainTextResponse
+from starlette.routing import Route
+from starlette.testclient import TestClient
+
+from middleware.drain import DrainMiddleware
+from utils.health_check import ReadinessState
+
+
+async def ok(request):
+    return PlainTextResponse('ok')
+
+
+@pytest.fixture
+def client() -> TestClient:
+    app = Starlette(routes=[
+        Route('/vector/retrieval', ok, methods=['POST']),
+        Route('/healthz', ok),
+        Route('/readyz', ok),
+    ])
+    app.add_middleware(DrainMiddleware)
+    return TestClient(app)
+
+
+@pytest.fixture
+def draining(monkeypatch):
+    monkeypatch.setattr(ReadinessState, 'draining', True)
+
+
+def test_not_draining_passes_through(client):
+    response = client.post('/vector/retrieval')
+    assert response.status_code == 200
+    assert response.text == 'ok'
+
+
+@pytest.mark.usefixtures('draining')
+def test_draining_rejects_with_connection_close(client):
+    response = client.post('/vector/retrieval')
+    assert response.status_code == 503
+    assert response.headers['connection'] == 'close'
+    body = response.json()
+    assert body['success'] is False
+    assert body['code'] == 503
+
+
+@pytest.mark.usefixtures('draining')
+def test_draining_rejects_versioned_path(client):
+    assert client.post('/v1/vector/retrieval').status_code == 503
+
+
+@pytest.mark.usefixtures('draining')
+@pytest.mark.parametrize('path', ['/healthz', '/readyz'])
+def test_draining_keeps_probes(client, path):
+    response = client.get(path)
+    assert response.status_code == 200
+    assert response.text == 'ok'
+
+
+@pytest.mark.usefixtures('draining')
+def test_draining_exempts_versioned_probe(client):
+    # 路由中没有 /v1/healthz，返回 404 说明请求通过了排空检查
+    assert client.get('/v1/healthz').status_code == 404
+
+
+@pytest.mark.usefixtures('draining')
+def test_draining_does_not_exempt_lookalike_path(client):
+    assert client.get('/healthzz').status_code == 503
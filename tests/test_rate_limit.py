@@ -0,0 +1,94 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : test_rate_limit
+# @Software: PyCharm
+import asyncio
+import time
+
+import pytest
+from starlette.responses import PlainTextResponse
+
+from middleware.rate_limit import RateLimitMiddleware
+from setting.setting import RateLimitSettings
+from tests.asgi import call
+
+
+async def ok(scope, receive, send):
+    await PlainTextResponse('ok')(scope, receive, send)
+
+
+@pytest.fixture
+def middleware(settings) -> RateLimitMiddleware:
+    settings.rate_limit = RateLimitSettings.model_validate({'enabled': True, 'per-minute': 60, 'burst': 5})
+    return RateLimitMiddleware(ok)
+
+
+def client_address(index: int) -> tuple[str, int]:
+    return f'10.{index // 65536 % 256}.{index // 256 % 256}.{index % 256}', 50000
+
+
+async def test_limit_is_per_client(middleware):
+    statuses = [(await call(middleware, 'POST', '/v1/vector/retrieval', client_address(0)))[0] for _ in range(6)]
+    assert statuses == [200] * 5 + [429]
+    # 其他客户端不受影响
+    status, _ = await call(middleware, 'POST', '/v1/vector/retrieval', client_address(1))
+    assert status == 200
+
+
+async def test_rejection_headers(middleware):
+    for _ in range(5):
+        await call(middleware, 'POST', '/v1/vector/retrieval')
+    status, headers = await call(middleware, 'POST', '/v1/vector/retrieval')
+    headers = dict(headers)
+    assert status == 429
+    assert headers[b'retry-after'] == b'1'
+    assert headers[b'x-ratelimit-remaining'] == b'0'
+    assert headers[b'x-ratelimit-cost'] == b'1'
+
+
+async def test_exempt_paths_are_not_counted(middleware):
+    for _ in range(10):
+        status, _ = await call(middleware, 'GET', '/v1/healthz')
+        assert status == 200
+    assert middleware.buckets == {}
+
+
+async def test_idle_buckets_are_evicted(middleware):
+    for index in range(100):
+        await call(middleware, 'POST', '/v1/vector/retrieval', client_address(index))
+    assert len(middleware.buckets) == 100
+
+    # 模拟经过足以补满令牌的空闲时间，下一次请求触发淘汰
+    for bucket in middleware.buckets.values():
+        bucket.updated_at -= 3600
+    middleware.evicted_at -= RateLimitMiddleware.EVICT_INTERVAL
+    await call(middleware, 'POST', '/v1/vector/retrieval', client_address(100))
+    assert list(middleware.buckets) == ['ip:' + client_address(100)[0]]
+
+
+@pytest.mark.benchmark
+@pytest.mark.parametrize('clients', [1, 100, 10000])
+async def test_benchmark_concurrent_clients(middleware, settings, clients):
+    """
+    并发请求分布在不同数量的客户端上：桶的读写之间没有 await，全部在事件循环中串行执行，
+    不存在锁竞争，客户端数量增加时单次请求的开销应基本不变
+    """
+    # 补充速率设为最低，运行期间不会补充出额外的令牌
+    settings.rate_limit = RateLimitSettings.model_validate({'enabled': True, 'per-minute': 1, 'burst': 5})
+    requests = 20000
+    start = time.perf_counter()
+    results = []
+    for offset in range(0, requests, 1000):
+        results += await asyncio.gather(*[
+            call(middleware, 'POST', '/v1/vector/retrieval', client_address(index % clients))
+            for index in range(offset, offset + 1000)
+        ])
+    elapsed = time.perf_counter() - start
+
+    allowed = sum(1 for status, _ in results if status == 200)
+    assert allowed == min(requests, clients * 5)
+    assert len(middleware.buckets) == clients
+    print(f"\n{clients} clients: {requests / elapsed:.0f} req/s, {elapsed / requests * 1e6:.1f} us/req")
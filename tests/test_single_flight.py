@@ -0,0 +1,119 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : test_single_flight
+# @Software: PyCharm
+import asyncio
+import time
+
+import pytest
+
+from extensions.vector.base_vector import BaseVector
+from utils.single_flight import SingleFlight
+
+
+class CountingLoader:
+    """模拟一次耗时的下游查询，记录实际执行的次数"""
+
+    def __init__(self, delay: float = 0.05, result: object = 1536):
+        self.delay = delay
+        self.result = result
+        self.calls = 0
+
+    async def __call__(self):
+        self.calls += 1
+        await asyncio.sleep(self.delay)
+        if isinstance(self.result, Exception):
+            raise self.result
+        return self.result
+
+
+async def test_concurrent_calls_share_one_load():
+    flight, loader = SingleFlight(), CountingLoader()
+    results = await asyncio.gather(*[flight.do('key', loader) for _ in range(50)])
+    assert results == [1536] * 50
+    assert loader.calls == 1
+
+
+async def test_different_keys_load_separately():
+    flight, loader = SingleFlight(), CountingLoader()
+    await asyncio.gather(flight.do('a', loader), flight.do('b', loader))
+    assert loader.calls == 2
+
+
+async def test_result_is_not_cached():
+    flight, loader = SingleFlight(), CountingLoader(delay=0)
+    await flight.do('key', loader)
+    await flight.do('key', loader)
+    assert loader.calls == 2
+
+
+async def test_exception_is_shared():
+    flight, loader = SingleFlight(), CountingLoader(result=ConnectionError('embedding backend down'))
+    results = await asyncio.gather(*[flight.do('key', loader) for _ in range(5)], return_exceptions=True)
+    assert all(isinstance(result, ConnectionError) for result in results)
+    assert loader.calls == 1
+
+
+async def test_cancelled_caller_does_not_cancel_shared_load():
+    flight, loader = SingleFlight(), CountingLoader()
+    first = asyncio.create_task(flight.do('key', loader))
+    second = asyncio.create_task(flight.do('key', loader))
+    await asyncio.sleep(0)
+    first.cancel()
+    assert await second == 1536
+    assert loader.calls == 1
+    with pytest.raises(asyncio.CancelledError):
+        await first
+
+
+class FakeEmbeddingModel:
+
+    def __init__(self, delay: float = 0.05):
+        self.delay = delay
+        self.calls = 0
+
+    async def create_embedding(self, texts):
+        self.calls += 1
+        await asyncio.sleep(self.delay)
+        return [[0.0] * 1536 for _ in texts]
+
+
+@pytest.fixture
+def embedding_model(monkeypatch) -> FakeEmbeddingModel:
+    model = FakeEmbeddingModel()
+
+    async def get_embedding_model(cls):
+        return model
+
+    monkeypatch.setattr(BaseVector, 'get_embedding_model', classmethod(get_embedding_model))
+    return model
+
+
+async def test_dimension_lookups_are_coalesced(embedding_model):
+    dimensions = await asyncio.gather(*[BaseVector.get_dimension() for _ in range(100)])
+    assert dimensions == [1536] * 100
+    assert embedding_model.calls == 1
+
+
+@pytest.mark.benchmark
+@pytest.mark.parametrize('callers', [10, 100, 1000])
+async def test_benchmark_thundering_herd(embedding_model, callers):
+    """缓存失效瞬间的并发探测：对比直接调用与经 SingleFlight 合并后的下游调用次数与耗时"""
+    start = time.perf_counter()
+    await asyncio.gather(*[BaseVector._load_dimension() for _ in range(callers)])
+    direct_elapsed, direct_calls = time.perf_counter() - start, embedding_model.calls
+
+    embedding_model.calls = 0
+    start = time.perf_counter()
+    await asyncio.gather(*[BaseVector.get_dimension() for _ in range(callers)])
+    coalesced_elapsed, coalesced_calls = time.perf_counter() - start, embedding_model.calls
+
+    assert direct_calls == callers
+    assert coalesced_calls == 1
+    print(
+        f"\n{callers} callers: direct {direct_calls} calls in {direct_elapsed * 1000:.1f}ms, "
+        f"single-flight {coalesced_calls} call in {coalesced_elapsed * 1000:.1f}ms"
+    )
@@ -2,10 +2,112 @@
 # -*- coding: utf-8 -*-
 
 import asyncio
-from typing import Dict, Any
+import time
+from datetime import datetime, timezone
+from typing import Dict, Any, Awaitable, Callable, Tuple
 from loguru import logger
+from prometheus_client import Counter
 from extensions.ext_manager import ExtManager
 from setting.setting import get_we0_index_settings
+from utils.single_flight import SingleFlight
+
+VECTOR_RECONNECTS = Counter(
+    'we0_index_vector_reconnects_total',
+    'Vector database connection resets after a failed health check'
+)
+
+class ReadinessState:
+    """启动完成后置为 ready，开始关闭时立即撤销，便于负载均衡先摘除流量"""
+    ready: bool = False
+    draining: bool = False  # 收到退出信号后置为 True，新请求由 DrainMiddleware 拒绝
+
+
+class HealthChecker:
+    """
+    并发执行已注册的依赖检查，每项检查有独立超时，汇总结果缓存 cache_ttl 秒，
+    避免负载均衡频繁探测时每次都打到依赖上；缓存过期时并发的探测共享同一轮检查
+    """
+
+    def __init__(self, cache_ttl: float, timeout: float):
+        self.cache_ttl = cache_ttl
+        self.timeout = timeout
+        self._checks: Dict[str, Tuple[Callable[[], Awaitable[Any]], float]] = {}
+        self._snapshot: Dict[str, Dict[str, Any]] | None = None
+        self._checked_at = 0.0
+        self._flight = SingleFlight()
+
+    def register_check(self, name: str, fn: Callable[[], Awaitable[Any]], timeout: float | None = None) -> None:
+        """fn 抛出异常或超时即视为不健康"""
+        self._checks[name] = (fn, timeout or self.timeout)
+
+    @staticmethod
+    async def _run_check(fn: Callable[[], Awaitable[Any]], timeout: float) -> Dict[str, Any]:
+        start = time.perf_counter()
+        try:
+            await asyncio.wait_for(fn(), timeout=timeout)
+            status = {"status": "healthy"}
+        except Exception as e:
+            status = {"status": "unhealthy", "error": f"{type(e).__name__}: {e}"}
+        status["latency_ms"] = round((time.perf_counter() - start) * 1000, 2)
+        status["checked_at"] = datetime.now(timezone.utc).isoformat()
+        return status
+
+    async def _run_all(self) -> Dict[str, Dict[str, Any]]:
+        names = list(self._checks)
+        results = await asyncio.gather(*(self._run_check(*self._checks[name]) for name in names))
+        self._snapshot = dict(zip(names, results))
+        self._checked_at = time.monotonic()
+        return self._snapshot
+
+    async def check(self) -> Dict[str, Dict[str, Any]]:
+        if self._snapshot is not None and time.monotonic() - self._checked_at < self.cache_ttl:
+            return self._snapshot
+        return await self._flight.do('check', self._run_all)
+
+
+health_checker = HealthChecker(
+    cache_ttl=get_we0_index_settings().health.cache_ttl,
+    timeout=get_we0_index_settings().health.timeout,
+)
+health_checker.register_check("vector_database", lambda: ExtManager.vector.ping())
+
+
+async def monitor_vector_database(interval: float) -> None:
+    """定期探活向量库，失败时丢弃连接池以便恢复后重新建立连接"""
+    healthy = True
+    while True:
+        await asyncio.sleep(interval)
+        # 不经过 health_checker 的缓存，每轮都实际探测一次
+        status = await HealthChecker._run_check(ExtManager.vector.ping, get_we0_index_settings().health.timeout)
+        if status["status"] == "healthy":
+            if not healthy:
+                logger.info("Vector database connection recovered")
+            healthy = True
+            continue
+        logger.warning(f"Vector database health check failed, resetting connections: {status['error']}")
+        healthy = False
+        VECTOR_RECONNECTS.inc()
+        try:
+            await ExtManager.vector.reset()
+        except Exception as e:
+            logger.error(f"Failed to reset vector database connections: {e}")
+
+
+async def readiness_check() -> Dict[str, Any]:
+    """Check whether the service can accept traffic"""
+    results = {
+        "ready": ReadinessState.ready,
+        "services": {}
+    }
+    if not ReadinessState.ready:
+        return results
+
+    results["services"] = await health_checker.check()
+    results["ready"] = all(
+        health["status"] == "healthy" for health in results["services"].values()
+    )
+    return results
+
 
 async def check_vector_database_health() -> Dict[str, Any]:
     """Check if vector database is healthy and accessible"""
@@ -18,9 +18,9 @@ class Helper:
     _encoders_cache = {}
 
     @staticmethod
-    def generate_text_hash(text: str) -> str:
+    def generate_text_hash(text: str | bytes) -> str:
         """生成文本的SHA-256哈希值"""
-        return sha256(text.encode()).hexdigest()
+        return sha256(text.encode() if isinstance(text, str) else text).hexdigest()
 
     @staticmethod
     def calculate_tokens(
@@ -0,0 +1,23 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : path_match
+# @Software: PyCharm
+from typing import Iterable
+
+from router.versioning import unversioned_path
+
+
+def match_path_prefix(path: str, prefixes: Iterable[str]) -> bool:
+    """
+    按路径段匹配前缀，各中间件的 exempt、scopes 等配置统一使用：
+    /health 匹配 /health 与 /health/...，不匹配 /healthz；path 先去掉 /v1 等版本前缀，'/' 匹配所有路径
+    """
+    path = unversioned_path(path)
+    for prefix in prefixes:
+        prefix = prefix.rstrip('/')
+        if not prefix or path == prefix or path.startswith(prefix + '/'):
+            return True
+    return False
@@ -0,0 +1,16 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : request_context
+# @Software: PyCharm
+from contextvars import ContextVar
+
+# 由 RequestIdMiddleware 设置；放在 utils 中，domain 与 middleware 均可引用而不产生循环导入
+request_id_context: ContextVar[str | None] = ContextVar('request_id', default=None)
+
+
+def get_request_id() -> str | None:
+    """获取当前请求的 request id，请求上下文之外返回 None"""
+    return request_id_context.get()
@@ -0,0 +1,39 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+# @Time    : 2026/10/14
+# @Author  : .*?
+# @Email   : amashiro2233@gmail.com
+# @File    : single_flight
+# @Software: PyCharm
+import asyncio
+from typing import Awaitable, Callable, Dict, Hashable, TypeVar
+
+T = TypeVar('T')
+
+
+class SingleFlight:
+    """
+    合并同一 key 的并发调用：首个调用者执行 fn，其余调用者等待并共享同一结果（或异常）
+    调用结束后即移除 key，不缓存结果
+    共享的调用被 asyncio.shield 保护：任一调用者（包括首个调用者）因超时、客户端断开等原因被取消时，
+    只是该调用者不再等待，fn 仍会在后台执行完毕，避免连带中断等待同一结果的其他调用者
+    """
+
+    def __init__(self):
+        self._calls: Dict[Hashable, asyncio.Future] = {}
+
+    async def do(self, key: Hashable, fn: Callable[[], Awaitable[T]]) -> T:
+        future = self._calls.get(key)
+        if future is not None:
+            # shield 防止某个等待者被取消时连带取消共享的调用
+            return await asyncio.shield(future)
+        future = asyncio.ensure_future(fn())
+        self._calls[key] = future
+        future.add_done_callback(lambda done: self._done(key, done))
+        return await asyncio.shield(future)
+
+    def _done(self, key: Hashable, future: asyncio.Future):
+        self._calls.pop(key, None)
+        # 所有等待者都已取消时异常无人读取，这里读取一次避免 "exception was never retrieved" 告警
+        if not future.cancelled():
+            future.exception()